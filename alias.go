@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// canonicalDevID returns the id of the dev known by id either directly or
+// through one of their aliases, or an empty string if there is none.
+func (d *data) canonicalDevID(id string) string {
+	if id == "" || d.Devs == nil {
+		return ""
+	}
+
+	if _, ok := d.Devs[id]; ok {
+		return id
+	}
+
+	for devID, dev := range d.Devs {
+		for _, alias := range dev.Aliases {
+			if alias == id {
+				return devID
+			}
+		}
+	}
+
+	return ""
+}
+
+func (d *data) addDevAliases(id string, aliases []string) error {
+	devID := d.canonicalDevID(id)
+	if devID == "" {
+		return errors.Errorf("no dev with id %s found%s", id, d.didYouMean(id))
+	}
+	dev := d.Devs[devID]
+
+	for _, alias := range aliases {
+		if alias == "" {
+			return errors.New("invalid alias")
+		}
+		if otherID := d.canonicalDevID(alias); otherID != "" {
+			if otherID == devID {
+				continue
+			}
+			return errors.Errorf("alias %s is already used by dev %s", alias, otherID)
+		}
+		dev.Aliases = append(dev.Aliases, alias)
+	}
+
+	return nil
+}
+
+// addDevEmails adds other emails the dev commits with.
+func (d *data) addDevEmails(id string, emails []string) error {
+	devID := d.canonicalDevID(id)
+	if devID == "" {
+		return errors.Errorf("no dev with id %s found%s", id, d.didYouMean(id))
+	}
+	dev := d.Devs[devID]
+
+	for _, email := range emails {
+		if !strings.Contains(email, "@") {
+			return errors.Errorf("invalid email %s", email)
+		}
+		if otherID, _ := d.lookupDevByEmail(email); otherID != "" {
+			if otherID == devID {
+				continue
+			}
+			return errors.Errorf("email %s is already used by dev %s", email, otherID)
+		}
+		dev.Emails = append(dev.Emails, email)
+	}
+
+	return nil
+}
+
+func (d *data) retireDev(id string, retired bool) error {
+	dev := d.lookupDev(id)
+	if dev == nil {
+		return errors.Errorf("no dev with id %s found%s", id, d.didYouMean(id))
+	}
+
+	dev.Retired = retired
+
+	return nil
+}
+
+// retiredError is returned when a retired dev is used to credit a
+// commit, or is added to a repo or team.
+func retiredError(id string) error {
+	return errors.Errorf("dev %s is retired and can no longer be credited", id)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAliasTestData() *data {
+	return &data{
+		Devs: map[string]*dev{
+			"km": &dev{Name: "Karan Misra", Email: "karan@beef.com", Aliases: []string{"kidoman"}},
+			"ak": &dev{Name: "akshat", Email: "akshat@beef.com"},
+			"an": &dev{Name: "Anand Shankar", Email: "anand@beef.com", Retired: true},
+		},
+		Repos: map[string]*repo{
+			"/a": &repo{Devs: []string{"kidoman", "an"}},
+		},
+		Teams: map[string][]string{
+			"payments": {"km", "ak", "an"},
+		},
+	}
+}
+
+func TestDataCanonicalDevID(t *testing.T) {
+	d := newAliasTestData()
+
+	assert.Equal(t, "km", d.canonicalDevID("km"))
+	assert.Equal(t, "km", d.canonicalDevID("kidoman"))
+	assert.Equal(t, "", d.canonicalDevID("karan"))
+	assert.Equal(t, "", d.canonicalDevID(""))
+
+	assert.Equal(t, d.Devs["km"], d.lookupDev("kidoman"))
+	assert.Equal(t, "km", d.resolveDevRef("kidoman"))
+}
+
+func TestDataAddDevAliases(t *testing.T) {
+	d := newAliasTestData()
+
+	assert.NoError(t, d.addDevAliases("kidoman", []string{"karan", "kidoman"}))
+	assert.Equal(t, []string{"kidoman", "karan"}, d.Devs["km"].Aliases)
+
+	err := d.addDevAliases("ak", []string{"karan"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "alias karan is already used by dev km", err.Error())
+	}
+
+	err = d.addDevAliases("ak", []string{"an"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "alias an is already used by dev an", err.Error())
+	}
+
+	err = d.addDevAliases("shobhit", []string{"sh"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "no dev with id shobhit found", err.Error())
+	}
+}
+
+func TestDataAddDevEmails(t *testing.T) {
+	d := newAliasTestData()
+
+	assert.NoError(t, d.addDevEmails("kidoman", []string{"karan@home.com", "Karan@Beef.com"}))
+	assert.Equal(t, []string{"karan@home.com"}, d.Devs["km"].Emails)
+
+	id, _ := d.lookupDevByEmail("KARAN+x@home.com")
+	assert.Equal(t, "km", id)
+
+	err := d.addDevEmails("ak", []string{"karan@home.com"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "email karan@home.com is already used by dev km", err.Error())
+	}
+
+	err = d.addDevEmails("ak", []string{"akshat"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid email akshat", err.Error())
+	}
+
+	err = d.addDevEmails("shobhit", []string{"sh@beef.com"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "no dev with id shobhit found", err.Error())
+	}
+}
+
+func TestDataRetireDev(t *testing.T) {
+	d := newAliasTestData()
+
+	assert.NoError(t, d.retireDev("kidoman", true))
+	assert.True(t, d.Devs["km"].Retired)
+
+	assert.NoError(t, d.retireDev("an", false))
+	assert.False(t, d.Devs["an"].Retired)
+
+	err := d.retireDev("shobhit", true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no dev with id shobhit found", err.Error())
+	}
+}
+
+func TestRetiredDevs(t *testing.T) {
+	d := newAliasTestData()
+
+	assert.Equal(t, []string{"km", "ak"}, d.expandDevIDs([]string{"@payments"}))
+	assert.Equal(t, []string{"km"}, d.expandDevIDs(d.Repos["/a"].Devs))
+
+	err := d.validateDevs([]string{"ak", "an"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "dev an is retired and can no longer be credited", err.Error())
+	}
+
+	err = d.addTeam("search", []string{"an"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "dev an is retired and can no longer be credited", err.Error())
+	}
+}
+
+func TestDataRemoveDevWithAlias(t *testing.T) {
+	d := newAliasTestData()
+
+	assert.NoError(t, d.removeDev("kidoman"))
+	assert.NotContains(t, d.Devs, "km")
+	assert.Equal(t, []string{"an"}, d.Repos["/a"].Devs)
+	assert.Equal(t, []string{"ak", "an"}, d.Teams["payments"])
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Kinds of problems the audit finds.
+const (
+	auditMissingCoAuthor  = "missing-co-author"
+	auditUnknownIssueID   = "unknown-issue-id"
+	auditUnknownCoAuthor  = "unknown-co-author"
+	auditUnresolvedPrefix = "unresolved-prefix"
+)
+
+// finding is a problem with a commit, like one made without the hook.
+type finding struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+var auditKinds = []string{auditMissingCoAuthor, auditUnknownIssueID, auditUnknownCoAuthor, auditUnresolvedPrefix}
+
+func checkAuditKinds(kinds []string) error {
+	for _, kind := range kinds {
+		if !contains(auditKinds, kind) {
+			return errors.Errorf("unknown problem kind %s, expected one of %s", kind, strings.Join(auditKinds, ", "))
+		}
+	}
+	return nil
+}
+
+// sessionPartners are the devs the author of c was in a session with at the
+// time of c, going by the sessions of the other commits. A session is taken
+// to last for gap around its commits.
+func (d *data) sessionPartners(c *commit, sessions []*session, gap time.Duration) []string {
+	author := d.contributorID(c.author.Email)
+
+	var partners []string
+	for _, s := range sessions {
+		if !contains(s.ids, author) {
+			continue
+		}
+		if c.date.Before(s.start.Add(-gap)) || c.date.After(s.end.Add(gap)) {
+			continue
+		}
+		for _, id := range s.ids {
+			if id != author && !contains(partners, id) {
+				partners = append(partners, id)
+			}
+		}
+	}
+	return partners
+}
+
+// audit finds the commits made without co-authors while their author was
+// pairing, with issue ids none of the trackers know, with co-authors not in
+// the roster, or with an unresolved [..] block left in their subject.
+// Sessions are found from around, which can go past the commits audited.
+func (d *data) audit(commits, around []*commit, issues *issueMatcher, gap time.Duration) []*finding {
+	sessions := d.sessions(around, gap)
+
+	var findings []*finding
+	for _, c := range commits {
+		subject := msgSubject(c.msg)
+		add := func(kind, format string, args ...interface{}) {
+			findings = append(findings, &finding{
+				Hash:    c.hash,
+				Subject: subject,
+				Kind:    kind,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		if len(d.contributors(c)) == 1 {
+			if partners := d.sessionPartners(c, sessions, gap); len(partners) != 0 {
+				add(auditMissingCoAuthor, "no co-author while pairing with %s", strings.Join(partners, ", "))
+			}
+		}
+
+		if id := issueIDTrailer(c.msg); id != "" && !issues.match(id) {
+			add(auditUnknownIssueID, "issue id %s does not match the trackers (%s)", id, issues)
+		}
+
+		for _, dev := range c.coAuthors {
+			if id, _ := d.lookupDevByEmail(dev.Email); id == "" {
+				add(auditUnknownCoAuthor, "unknown co-author %s", dev)
+			}
+		}
+
+		_, rest := splitConventionalHeader(subject)
+		if block := prefixRegexp.FindString(rest); block != "" {
+			add(auditUnresolvedPrefix, "subject starts with unresolved %s", block)
+		}
+	}
+	return findings
+}
+
+func writeAudit(w io.Writer, findings []*finding, commits int, asJSON bool) error {
+	if asJSON {
+		if findings == nil {
+			findings = []*finding{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(findings)
+	}
+
+	var (
+		hash    string
+		flagged int
+	)
+	for _, f := range findings {
+		if f.Hash != hash {
+			hash = f.Hash
+			flagged++
+			fmt.Fprintf(w, "%s %s\n", shortHash(f.Hash), f.Subject)
+		}
+		fmt.Fprintf(w, "  - %s\n", f.Message)
+	}
+	fmt.Fprintf(w, "%d problems in %d of %d commits\n", len(findings), flagged, commits)
+	return nil
+}
+
+// auditTip is the revision revRange ends at.
+func auditTip(revRange string) string {
+	tip := revRange
+	if i := strings.LastIndex(revRange, ".."); i != -1 {
+		tip = strings.TrimLeft(revRange[i+2:], ".")
+	}
+	if tip == "" {
+		return "HEAD"
+	}
+	return tip
+}
+
+// repoAudit audits the commits in revRange of the current repo, against the
+// trackers configured for it, and fails if anything was found. Findings of
+// the ignored kinds are left out, and those of the warned kinds are
+// reported without failing.
+func (d *data) repoAudit(w io.Writer, wd, revRange string, gap time.Duration, ignore, warn []string, asJSON bool) error {
+	if revRange == "" {
+		return errors.New("invalid range, expected like from..to")
+	}
+	if gap <= 0 {
+		return errors.Errorf("invalid session gap %s", gap)
+	}
+	if err := checkAuditKinds(append(append([]string(nil), ignore...), warn...)); err != nil {
+		return err
+	}
+
+	var trackers []*tracker
+	repoPath, repo := d.lookupRepo(wd)
+	if repo != nil {
+		trackers = repo.Trackers
+	}
+	issues, err := newIssueMatcher(trackers)
+	if err != nil {
+		return errors.Wrapf(err, "invalid trackers for repo %s", repoPath)
+	}
+
+	commits, err := readHistory("", "--no-merges", revRange)
+	if err != nil {
+		return err
+	}
+
+	// The first commits of the range may have been made right after a
+	// session before it, so sessions are looked for from gap before.
+	around := commits
+	if len(commits) != 0 {
+		first := commits[0].date
+		for _, c := range commits {
+			if c.date.Before(first) {
+				first = c.date
+			}
+		}
+		since := "--since=" + first.Add(-gap).Format(time.RFC3339)
+		if around, err = readHistory("", "--no-merges", since, auditTip(revRange)); err != nil {
+			return err
+		}
+	}
+
+	var findings []*finding
+	failing := 0
+	for _, f := range d.audit(commits, around, issues, gap) {
+		if contains(ignore, f.Kind) {
+			continue
+		}
+		findings = append(findings, f)
+		if !contains(warn, f.Kind) {
+			failing++
+		}
+	}
+
+	if err := writeAudit(w, findings, len(commits), asJSON); err != nil {
+		return err
+	}
+
+	if failing != 0 {
+		return errors.Errorf("found %d problems in %s", failing, revRange)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionPartners(t *testing.T) {
+	d := trendData()
+	at := func(hour, min int) time.Time {
+		return time.Date(2019, 3, 4, hour, min, 0, 0, time.UTC)
+	}
+	sessions := []*session{
+		{ids: []string{"anand", "karan"}, start: at(10, 0), end: at(11, 0)},
+		{ids: []string{"akshat", "karan"}, start: at(12, 0), end: at(12, 0)},
+	}
+	karan := &dev{Name: "Karan Misra", Email: "karan@beef.com"}
+	gap := 45 * time.Minute
+
+	assert.Equal(t, []string{"anand"}, d.sessionPartners(&commit{author: karan, date: at(9, 15)}, sessions, gap))
+	assert.Equal(t, []string{"anand", "akshat"}, d.sessionPartners(&commit{author: karan, date: at(11, 30)}, sessions, gap))
+	assert.Empty(t, d.sessionPartners(&commit{author: karan, date: at(9, 14)}, sessions, gap))
+	assert.Empty(t, d.sessionPartners(&commit{author: karan, date: at(13, 0)}, sessions, gap))
+	assert.Empty(t, d.sessionPartners(&commit{author: &dev{Email: "akshat@beef.com"}, date: at(10, 30)}, sessions, gap))
+}
+
+// stubAuditGit stubs git with the range commits, and the commits from
+// before the range too when the history is read for sessions.
+func stubAuditGit(inRange, before string) (*[]string, func()) {
+	calls, restore := stubGit(nil, "")
+	stubbed := gitRun
+	gitRun = func(args ...string) (string, error) {
+		stubbed(args...)
+		switch {
+		case args[0] == "check-mailmap":
+			return strings.Join(args[1:], "\n"), nil
+		case strings.HasPrefix(args[len(args)-2], "--since="):
+			return inRange + before, nil
+		}
+		return inRange, nil
+	}
+	return calls, restore
+}
+
+func TestRepoAudit(t *testing.T) {
+	d := trendData()
+	d.Repos = map[string]*repo{
+		"/r": {Trackers: []*tracker{{Type: "jira"}}},
+	}
+
+	output := logRecord("gggggggggg", "Karan Misra <karan@beef.com>", 1551703600, "Update docs\n") +
+		logRecord("ffffffffff", "Karan Misra <karan@beef.com>", 1551701800, "Fix typo\n") +
+		logRecord("eeeeeeeeee", "Akshat Shah <akshat@beef.com>", 1551700600, "Tidy up\n") +
+		logRecord("dddddddddd", "Karan Misra <karan@beef.com>", 1551700000,
+			"Add refunds\n\nIssue-id: PAY-12\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n") +
+		logRecord("cccccccccc", "Akshat Shah <akshat@beef.com>", 1551500000, "[ak|xx] Bump deps\n") +
+		logRecord("bbbbbbbbbb", "Anand Shankar <anand@beef.com>", 1551400000,
+			"feat: [km] Add charges\n\nIssue-id: #7\n\nCo-authored-by: Someone <some@one.com>\n") +
+		logRecord("aaaaaaaaaa", "Karan Misra <karan@beef.com>", 1551300000, "Add README\n")
+
+	// Paired on right before the range starts.
+	before := logRecord("9999999999", "Karan Misra <karan@beef.com>", 1551298800,
+		"Add skeleton\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\n")
+
+	calls, restore := stubAuditGit(output, before)
+	var buf bytes.Buffer
+	err := d.repoAudit(&buf, "/r", "v1..v2", 45*time.Minute, nil, nil, false)
+	restore()
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "found 6 problems in v1..v2", err.Error())
+	}
+	assert.Equal(t, "log "+historyFormat+" --no-merges v1..v2", (*calls)[0])
+	since := time.Unix(1551300000, 0).Add(-45 * time.Minute).Format(time.RFC3339)
+	assert.Equal(t, "log "+historyFormat+" --no-merges --since="+since+" v2", (*calls)[2])
+	// The solo commits made after the session ended, or by someone not in
+	// it, are fine.
+	assert.Equal(t, "fffffff Fix typo\n"+
+		"  - no co-author while pairing with anand\n"+
+		"ccccccc [ak|xx] Bump deps\n"+
+		"  - subject starts with unresolved [ak|xx]\n"+
+		"bbbbbbb feat: [km] Add charges\n"+
+		"  - issue id #7 does not match the trackers (jira)\n"+
+		"  - unknown co-author Someone <some@one.com>\n"+
+		"  - subject starts with unresolved [km]\n"+
+		"aaaaaaa Add README\n"+
+		"  - no co-author while pairing with akshat\n"+
+		"6 problems in 4 of 7 commits\n", buf.String())
+
+	_, restore = stubAuditGit(output, before)
+	buf.Reset()
+	err = d.repoAudit(&buf, "/r", "v1..v2", 45*time.Minute, nil, nil, true)
+	restore()
+
+	assert.Error(t, err)
+	assert.Contains(t, buf.String(), `{
+    "hash": "ffffffffff",
+    "subject": "Fix typo",
+    "kind": "missing-co-author",
+    "message": "no co-author while pairing with anand"
+  },`)
+
+	// Ignored kinds are left out, and warned kinds do not fail the audit.
+	_, restore = stubAuditGit(output, before)
+	buf.Reset()
+	err = d.repoAudit(&buf, "/r", "v1..v2", 45*time.Minute,
+		[]string{auditUnresolvedPrefix, auditUnknownCoAuthor}, []string{auditMissingCoAuthor}, false)
+	restore()
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "found 1 problems in v1..v2", err.Error())
+	}
+	assert.Equal(t, "fffffff Fix typo\n"+
+		"  - no co-author while pairing with anand\n"+
+		"bbbbbbb feat: [km] Add charges\n"+
+		"  - issue id #7 does not match the trackers (jira)\n"+
+		"aaaaaaa Add README\n"+
+		"  - no co-author while pairing with akshat\n"+
+		"3 problems in 3 of 7 commits\n", buf.String())
+
+	_, restore = stubAuditGit(output, before)
+	buf.Reset()
+	err = d.repoAudit(&buf, "/r", "v1..v2", 45*time.Minute,
+		[]string{auditUnresolvedPrefix, auditUnknownCoAuthor}, []string{auditMissingCoAuthor, auditUnknownIssueID}, false)
+	restore()
+	assert.NoError(t, err)
+
+	// Without the solo commit and with the default trackers, the rest of
+	// the range passes.
+	clean := logRecord("dddddddddd", "Karan Misra <karan@beef.com>", 1551700000,
+		"Add refunds\n\nIssue-id: #7\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n")
+	_, restore = stubAuditGit(clean, "")
+	buf.Reset()
+	err = d.repoAudit(&buf, "/elsewhere", "v1..v2", 45*time.Minute, nil, nil, true)
+	restore()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "[]\n", buf.String())
+	}
+
+	err = d.repoAudit(&buf, "/r", "", 45*time.Minute, nil, nil, false)
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid range, expected like from..to", err.Error())
+	}
+
+	err = d.repoAudit(&buf, "/r", "v1..v2", 45*time.Minute, []string{"typo"}, nil, false)
+	if assert.Error(t, err) {
+		assert.Equal(t, "unknown problem kind typo, expected one of missing-co-author, unknown-issue-id, unknown-co-author, unresolved-prefix", err.Error())
+	}
+}
+
+func TestAuditTip(t *testing.T) {
+	assert.Equal(t, "v2", auditTip("v1..v2"))
+	assert.Equal(t, "v2", auditTip("v1...v2"))
+	assert.Equal(t, "HEAD", auditTip("origin/main.."))
+	assert.Equal(t, "main", auditTip("main"))
+}
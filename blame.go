@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// notCommitted is the hash git blame gives lines not committed yet.
+const notCommitted = "0000000000000000000000000000000000000000"
+
+// blameCommit is what git blame tells about a commit.
+type blameCommit struct {
+	hash   string
+	author string
+	date   time.Time
+}
+
+// blameLine is a line of the file blamed on a commit.
+type blameLine struct {
+	commit  *blameCommit
+	line    int
+	content string
+}
+
+// parseBlame parses the output of git blame --porcelain.
+func parseBlame(output string) ([]*blameLine, error) {
+	var (
+		lines   []*blameLine
+		commits = make(map[string]*blameCommit)
+		current *blameLine
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if current == nil {
+			// <hash> <original line> <final line> [<lines in group>]
+			fields := strings.Fields(line)
+			if len(fields) < 3 || len(fields[0]) != len(notCommitted) {
+				return nil, errors.Errorf("invalid blame header %q", line)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, errors.Errorf("invalid blame header %q", line)
+			}
+
+			c := commits[fields[0]]
+			if c == nil {
+				c = &blameCommit{hash: fields[0]}
+				commits[fields[0]] = c
+			}
+			current = &blameLine{commit: c, line: n}
+			continue
+		}
+
+		if strings.HasPrefix(line, "\t") {
+			current.content = line[1:]
+			lines = append(lines, current)
+			current = nil
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.Index(line, " "); i != -1 {
+			key, value = line[:i], line[i+1:]
+		}
+		switch key {
+		case "author":
+			current.commit.author = value
+		case "author-time":
+			unix, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid author-time of commit %s", current.commit.hash)
+			}
+			current.commit.date = time.Unix(unix, 0).UTC()
+		case "author-tz":
+			// Dates are shown in the author's time zone, like git does.
+			if t, err := time.Parse("-0700", value); err == nil {
+				current.commit.date = current.commit.date.In(t.Location())
+			}
+		}
+	}
+
+	// gitRun trims the output, which drops the tab of an empty last line.
+	if current != nil {
+		lines = append(lines, current)
+	}
+	return lines, nil
+}
+
+// blameCoAuthors reads the commits with the given hashes, and returns the
+// ids of the co-authors of each (other than its author).
+func (d *data) blameCoAuthors(hashes []string) (map[string][]string, error) {
+	coAuthors := make(map[string][]string)
+	if len(hashes) == 0 {
+		return coAuthors, nil
+	}
+
+	commits, err := readHistory("", append([]string{"--no-walk=unsorted"}, hashes...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range commits {
+		author := d.contributorID(c.author.Email)
+		var ids []string
+		for _, dev := range c.coAuthors {
+			if id := d.contributorID(dev.Email); id != author && !contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		coAuthors[c.hash] = ids
+	}
+	return coAuthors, nil
+}
+
+// blameHashes are the commits lines were blamed on, in order.
+func blameHashes(lines []*blameLine) []string {
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, l := range lines {
+		if h := l.commit.hash; h != notCommitted && !seen[h] {
+			seen[h] = true
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
+
+func writeBlame(w io.Writer, lines []*blameLine, coAuthors map[string][]string) {
+	var authorWidth, coAuthorWidth, lineWidth int
+	for _, l := range lines {
+		if n := len([]rune(l.commit.author)); n > authorWidth {
+			authorWidth = n
+		}
+		if n := len(strings.Join(coAuthors[l.commit.hash], ",")); n > coAuthorWidth {
+			coAuthorWidth = n
+		}
+		if n := len(strconv.Itoa(l.line)); n > lineWidth {
+			lineWidth = n
+		}
+	}
+
+	for _, l := range lines {
+		who := l.commit.author + strings.Repeat(" ", authorWidth-len([]rune(l.commit.author)))
+		if coAuthorWidth != 0 {
+			ids := ""
+			if len(coAuthors[l.commit.hash]) != 0 {
+				ids = "+" + strings.Join(coAuthors[l.commit.hash], ",")
+			}
+			who += fmt.Sprintf(" %-*s", coAuthorWidth+1, ids)
+		}
+		fmt.Fprintf(w, "%s (%s %s %*d) %s\n", shortHash(l.commit.hash), who, l.commit.date.Format("2006-01-02"), lineWidth, l.line, l.content)
+	}
+}
+
+// writeBlamePorcelain writes the output of git blame --porcelain with a
+// co-authors header, listing the ids of the co-authors, added to the
+// headers of each commit.
+func writeBlamePorcelain(w io.Writer, output string, coAuthors map[string][]string) {
+	var hash string
+	inHeaders := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(w, line)
+
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			inHeaders = false
+		case !inHeaders:
+			// A blank line is not expected here, and is passed on as is.
+			if fields := strings.Fields(line); len(fields) != 0 {
+				hash, inHeaders = fields[0], true
+			}
+		case strings.HasPrefix(line, "summary ") && len(coAuthors[hash]) != 0:
+			fmt.Fprintf(w, "co-authors %s\n", strings.Join(coAuthors[hash], ","))
+		}
+	}
+
+	// Put back the tab of an empty last line, trimmed by gitRun.
+	if inHeaders {
+		fmt.Fprintln(w, "\t")
+	}
+}
+
+// repoBlame writes who last changed each line of file in the current repo,
+// along with the co-authors of the commit. ranges are like the -L of git
+// blame.
+func (d *data) repoBlame(w io.Writer, file string, ranges []string, porcelain bool) error {
+	args := []string{"blame", "--porcelain"}
+	for _, r := range ranges {
+		args = append(args, "-L", r)
+	}
+	args = append(args, "--", file)
+
+	output, err := gitRun(args...)
+	if err != nil {
+		return errors.Wrap(err, "blame failed")
+	}
+
+	lines, err := parseBlame(output)
+	if err != nil {
+		return err
+	}
+
+	coAuthors, err := d.blameCoAuthors(blameHashes(lines))
+	if err != nil {
+		return err
+	}
+
+	if porcelain {
+		writeBlamePorcelain(w, output, coAuthors)
+	} else {
+		writeBlame(w, lines, coAuthors)
+	}
+	return nil
+}
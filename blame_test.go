@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	blameHashA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	blameHashB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+// blameOutput is like git blame --porcelain, as trimmed by gitRun.
+var blameOutput = blameHashA + ` 1 1 2
+author Karan Misra
+author-mail <karan@beef.com>
+author-time 1551700000
+author-tz +0530
+committer Karan Misra
+committer-mail <karan@beef.com>
+committer-time 1551700000
+committer-tz +0530
+summary Add refunds
+filename pay/refund.go
+	package pay
+` + blameHashA + ` 2 2
+	
+` + blameHashB + ` 5 3 1
+author Akshat Shah
+author-mail <akshat@beef.com>
+author-time 1551800000
+author-tz -0100
+committer Akshat Shah
+committer-mail <akshat@beef.com>
+committer-time 1551800000
+committer-tz -0100
+summary Round refunds
+previous cccccccccccccccccccccccccccccccccccccccc pay/refund.go
+filename pay/refund.go
+	func refund() {}
+` + notCommitted + ` 4 4 1
+author Not Committed Yet
+author-mail <not.committed.yet>
+author-time 1551900000
+author-tz +0000
+committer Not Committed Yet
+committer-mail <not.committed.yet>
+committer-time 1551900000
+committer-tz +0000
+summary Version of pay/refund.go from pay/refund.go
+previous ` + blameHashB + ` pay/refund.go
+filename pay/refund.go`
+
+func TestParseBlame(t *testing.T) {
+	lines, err := parseBlame(blameOutput)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, lines, 4)
+	assert.Equal(t, blameHashA, lines[0].commit.hash)
+	assert.Equal(t, "Karan Misra", lines[0].commit.author)
+	assert.Equal(t, "2019-03-04 17:16 +0530", lines[0].commit.date.Format("2006-01-02 15:04 -0700"))
+	assert.Equal(t, "package pay", lines[0].content)
+	assert.True(t, lines[0].commit == lines[1].commit)
+	assert.Equal(t, 2, lines[1].line)
+	assert.Equal(t, "", lines[1].content)
+	assert.Equal(t, 3, lines[2].line)
+	assert.Equal(t, "2019-03-05 14:33 -0100", lines[2].commit.date.Format("2006-01-02 15:04 -0700"))
+	assert.Equal(t, 4, lines[3].line)
+	assert.Equal(t, "", lines[3].content)
+
+	assert.Equal(t, []string{blameHashA, blameHashB}, blameHashes(lines))
+
+	_, err = parseBlame("fatal: no such path")
+	if assert.Error(t, err) {
+		assert.Equal(t, `invalid blame header "fatal: no such path"`, err.Error())
+	}
+
+	_, err = parseBlame(blameHashA + " 1 1 1\nauthor Karan Misra\nauthor-time soon\n\tpackage pay")
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid author-time of commit "+blameHashA+`: strconv.ParseInt: parsing "soon": invalid syntax`, err.Error())
+	}
+}
+
+func TestWriteBlamePorcelainBlankLine(t *testing.T) {
+	var buf bytes.Buffer
+	writeBlamePorcelain(&buf, "\n"+blameHashA+" 1 1 1\nsummary Line 1\n\tpackage pay", map[string][]string{blameHashA: {"anand"}})
+
+	assert.Equal(t, "\n"+blameHashA+" 1 1 1\nsummary Line 1\nco-authors anand\n\tpackage pay\n", buf.String())
+}
+
+func TestRepoBlame(t *testing.T) {
+	d := trendData()
+	d.Devs["karan"].Emails = []string{"karan@home.com"}
+
+	output := logRecord(blameHashA, "Karan Misra <karan@beef.com>", 1551700000,
+		"Add refunds\n\nCo-authored-by: Anand Shankar <anand@beef.com>\nCo-authored-by: Someone <some@one.com>\n") +
+		logRecord(blameHashB, "Akshat Shah <akshat@beef.com>", 1551800000,
+			"Round refunds\n\nCo-authored-by: Karan <karan@home.com>\n")
+
+	tests := []struct {
+		ranges    []string
+		porcelain bool
+		call      string
+		output    string
+	}{
+		{
+			call: "blame --porcelain -- pay/refund.go",
+			output: "" +
+				"aaaaaaa (Karan Misra       +anand,some@one.com 2019-03-04 1) package pay\n" +
+				"aaaaaaa (Karan Misra       +anand,some@one.com 2019-03-04 2) \n" +
+				"bbbbbbb (Akshat Shah       +karan              2019-03-05 3) func refund() {}\n" +
+				"0000000 (Not Committed Yet                     2019-03-06 4) \n",
+		},
+		{
+			ranges:    []string{"1,3", "/func/"},
+			porcelain: true,
+			call:      "blame --porcelain -L 1,3 -L /func/ -- pay/refund.go",
+		},
+	}
+
+	for _, tt := range tests {
+		calls, restore := stubGit(map[string]string{
+			"blame":         blameOutput,
+			"log":           output,
+			"check-mailmap": "Anand Shankar <anand@beef.com>\nSomeone <some@one.com>\nKaran <karan@home.com>",
+		}, "")
+
+		var buf bytes.Buffer
+		err := d.repoBlame(&buf, "pay/refund.go", tt.ranges, tt.porcelain)
+		restore()
+
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, []string{
+			tt.call,
+			"log " + historyFormat + " --no-walk=unsorted " + blameHashA + " " + blameHashB,
+			"check-mailmap Anand Shankar <anand@beef.com> Someone <some@one.com> Karan <karan@home.com>",
+		}, *calls)
+
+		if !tt.porcelain {
+			assert.Equal(t, tt.output, buf.String())
+			continue
+		}
+
+		assert.Contains(t, buf.String(), "summary Add refunds\nco-authors anand,some@one.com\nfilename pay/refund.go\n\tpackage pay\n")
+		assert.Contains(t, buf.String(), "summary Round refunds\nco-authors karan\nprevious ")
+		assert.Contains(t, buf.String(), "summary Version of pay/refund.go from pay/refund.go\nprevious ")
+		assert.Equal(t, blameOutput+"\n\t\n", stripCoAuthors(buf.String()))
+	}
+}
+
+func stripCoAuthors(porcelain string) string {
+	var b bytes.Buffer
+	for _, line := range bytes.SplitAfter([]byte(porcelain), []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("co-authors ")) {
+			b.Write(line)
+		}
+	}
+	return b.String()
+}
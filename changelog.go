@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Formats the changelog can be written in.
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+	formatText     = "text"
+)
+
+var changelogFormats = []string{formatMarkdown, formatJSON, formatText}
+
+// checkFormat fails unless format is one of formats.
+func checkFormat(format string, formats []string) error {
+	if !contains(formats, format) {
+		return errors.Errorf("unknown format %s, expected one of %s", format, strings.Join(formats, ", "))
+	}
+	return nil
+}
+
+// unlinkedTitle is what commits without an issue id are listed under.
+const unlinkedTitle = "Unlinked"
+
+// changelogIssue is the commits made for an issue. The issue id is empty
+// for the unlinked commits.
+type changelogIssue struct {
+	IssueID      string             `json:"issueId"`
+	Commits      []*changelogCommit `json:"commits"`
+	Contributors []string           `json:"contributors"`
+}
+
+type changelogCommit struct {
+	Hash         string   `json:"hash"`
+	Subject      string   `json:"subject"`
+	Contributors []string `json:"contributors"`
+}
+
+// issueIDTrailer returns the issue id in the Issue-id trailer of msg, as
+// written, whichever tracker it comes from.
+func issueIDTrailer(msg string) string {
+	scanner := bufio.NewScanner(strings.NewReader(msg))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, issueIDPrefix) {
+			return strings.TrimSpace(line[len(issueIDPrefix):])
+		}
+	}
+	return ""
+}
+
+// contributorNames are the names of the author and co-authors of c, as
+// known in the roster, sorted.
+func (d *data) contributorNames(c *commit) []string {
+	var names []string
+	for _, dev := range append([]*dev{c.author}, c.coAuthors...) {
+		name := dev.Name
+		if _, rdev := d.lookupDevByEmail(dev.Email); rdev != nil {
+			name = rdev.Name
+		}
+		if !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// changelog groups commits by their issue id, in the order the issues
+// first show up, followed by the unlinked commits.
+func (d *data) changelog(commits []*commit) []*changelogIssue {
+	var (
+		issues   []*changelogIssue
+		byID     = make(map[string]*changelogIssue)
+		unlinked = &changelogIssue{}
+	)
+
+	for _, c := range commits {
+		// GitHub ids are grouped with or without the #.
+		issueID := issueIDTrailer(c.msg)
+		key := strings.TrimPrefix(issueID, "#")
+
+		issue := unlinked
+		if issueID != "" {
+			issue = byID[key]
+			if issue == nil {
+				issue = &changelogIssue{IssueID: issueID}
+				byID[key] = issue
+				issues = append(issues, issue)
+			}
+		}
+
+		names := d.contributorNames(c)
+		issue.Commits = append(issue.Commits, &changelogCommit{
+			Hash:         c.hash,
+			Subject:      msgSubject(c.msg),
+			Contributors: names,
+		})
+		for _, name := range names {
+			if !contains(issue.Contributors, name) {
+				issue.Contributors = append(issue.Contributors, name)
+			}
+		}
+	}
+
+	if len(unlinked.Commits) != 0 {
+		issues = append(issues, unlinked)
+	}
+	for _, issue := range issues {
+		sort.Strings(issue.Contributors)
+	}
+	return issues
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func (i *changelogIssue) title() string {
+	if i.IssueID == "" {
+		return unlinkedTitle
+	}
+	return i.IssueID
+}
+
+func writeChangelog(w io.Writer, issues []*changelogIssue, format string) error {
+	if err := checkFormat(format, changelogFormats); err != nil {
+		return err
+	}
+
+	switch format {
+	case formatJSON:
+		if issues == nil {
+			issues = []*changelogIssue{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issues)
+
+	case formatMarkdown:
+		for i, issue := range issues {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "## %s\n\n", issue.title())
+			for _, c := range issue.Commits {
+				fmt.Fprintf(w, "- %s (%s)\n", c.Subject, shortHash(c.Hash))
+			}
+			fmt.Fprintf(w, "\nContributors: %s\n", strings.Join(issue.Contributors, ", "))
+		}
+		return nil
+
+	default:
+		for i, issue := range issues {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, issue.title())
+			for _, c := range issue.Commits {
+				fmt.Fprintf(w, "  %s %s\n", shortHash(c.Hash), c.Subject)
+			}
+			fmt.Fprintf(w, "  contributors: %s\n", strings.Join(issue.Contributors, ", "))
+		}
+		return nil
+	}
+}
+
+// repoChangelog writes the changelog of the current repo for the commits
+// in revRange, like v1.0..v1.1, oldest first.
+func (d *data) repoChangelog(w io.Writer, revRange, format string) error {
+	if !strings.Contains(revRange, "..") {
+		return errors.Errorf("invalid range %q, expected like from..to", revRange)
+	}
+	if err := checkFormat(format, changelogFormats); err != nil {
+		return err
+	}
+
+	commits, err := readHistory("", "--no-merges", "--reverse", revRange)
+	if err != nil {
+		return err
+	}
+
+	return writeChangelog(w, d.changelog(commits), format)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueIDTrailer(t *testing.T) {
+	assert.Equal(t, "PAY-12", issueIDTrailer("Line 1\n\nIssue-id: PAY-12\n\nCo-authored-by: A <a@b.com>"))
+	assert.Equal(t, "#12", issueIDTrailer("Line 1\n\nIssue-id: #12"))
+	assert.Equal(t, "", issueIDTrailer("Line 1\n\nSee Issue-id: PAY-12"))
+}
+
+func TestChangelog(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan": {Name: "Karan Misra", Email: "karan@beef.com"},
+			"anand": {Name: "Anand Shankar", Email: "anand@beef.com"},
+		},
+	}
+
+	output := logRecord("aaaaaaaaaa", "Karan <karan@beef.com>", 1551500000,
+		"Add refunds\n\nIssue-id: PAY-12\n\nCo-authored-by: A <anand@beef.com>\n") +
+		logRecord("bbbbbbbbbb", "Ext <ext@corp.com>", 1551600000, "Bump deps\n") +
+		logRecord("cccccccccc", "Anand Shankar <anand@beef.com>", 1551700000, "Fix CI\n\nIssue-id: #7\n") +
+		logRecord("dddddddddd", "Karan Misra <karan@beef.com>", 1551800000, "Round refunds\n\nIssue-id: PAY-12\n") +
+		logRecord("eeeeeeeeee", "Karan Misra <karan@beef.com>", 1551900000, "Fix CI again\n\nIssue-id: 7\n")
+
+	tests := []struct {
+		format string
+		output string
+		errMsg string
+	}{
+		{
+			format: formatMarkdown,
+			output: "## PAY-12\n\n" +
+				"- Add refunds (aaaaaaa)\n" +
+				"- Round refunds (ddddddd)\n\n" +
+				"Contributors: Anand Shankar, Karan Misra\n\n" +
+				"## #7\n\n" +
+				"- Fix CI (ccccccc)\n" +
+				"- Fix CI again (eeeeeee)\n\n" +
+				"Contributors: Anand Shankar, Karan Misra\n\n" +
+				"## Unlinked\n\n" +
+				"- Bump deps (bbbbbbb)\n\n" +
+				"Contributors: Ext\n",
+		},
+		{
+			format: formatText,
+			output: "PAY-12\n" +
+				"  aaaaaaa Add refunds\n" +
+				"  ddddddd Round refunds\n" +
+				"  contributors: Anand Shankar, Karan Misra\n\n" +
+				"#7\n" +
+				"  ccccccc Fix CI\n" +
+				"  eeeeeee Fix CI again\n" +
+				"  contributors: Anand Shankar, Karan Misra\n\n" +
+				"Unlinked\n" +
+				"  bbbbbbb Bump deps\n" +
+				"  contributors: Ext\n",
+		},
+		{
+			format: "html",
+			errMsg: "unknown format html, expected one of markdown, json, text",
+		},
+	}
+
+	for _, tt := range tests {
+		calls, restore := stubGit(map[string]string{"log": output, "check-mailmap": "A <anand@beef.com>"}, "")
+
+		var buf bytes.Buffer
+		err := d.repoChangelog(&buf, "v1..v2", tt.format)
+		restore()
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			assert.Empty(t, *calls)
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "log "+historyFormat+" --no-merges --reverse v1..v2", (*calls)[0])
+			assert.Equal(t, tt.output, buf.String())
+		}
+	}
+
+	err := d.repoChangelog(nil, "v1", formatText)
+	if assert.Error(t, err) {
+		assert.Equal(t, `invalid range "v1", expected like from..to`, err.Error())
+	}
+}
+
+func TestWriteChangelogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeChangelog(&buf, []*changelogIssue{
+		{
+			Commits:      []*changelogCommit{{Hash: "abc", Subject: "Bump deps", Contributors: []string{"Ext"}}},
+			Contributors: []string{"Ext"},
+		},
+	}, formatJSON))
+
+	assert.Equal(t, `[
+  {
+    "issueId": "",
+    "commits": [
+      {
+        "hash": "abc",
+        "subject": "Bump deps",
+        "contributors": [
+          "Ext"
+        ]
+      }
+    ],
+    "contributors": [
+      "Ext"
+    ]
+  }
+]
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, writeChangelog(&buf, nil, formatJSON))
+	assert.Equal(t, "[]\n", buf.String())
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
@@ -13,6 +17,11 @@ import (
 var (
 	version = "0.3.4"
 	d       *data
+
+	// readOnly is set by commands which do not change the config, so it is
+	// not written back. A long running command would otherwise undo the
+	// changes made in the meantime by others.
+	readOnly bool
 )
 
 func main() {
@@ -57,6 +66,10 @@ func main() {
 	}
 
 	app.After = func(c *cli.Context) error {
+		if readOnly {
+			return nil
+		}
+
 		cfg := c.String("config")
 
 		f, err := os.OpenFile(cfg, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
@@ -74,10 +87,35 @@ func main() {
 
 	app.Commands = []cli.Command{
 		showConfigCommand,
+		previewCommand,
 		addInfoCommand,
+		checkMsgCommand,
 		addDevCommand,
+		removeDevCommand,
+		addAliasCommand,
+		addEmailCommand,
+		retireDevCommand,
+		addTeamCommand,
+		removeTeamCommand,
 		initCommand,
 		setDevsCommand,
+		pairCommand,
+		statsCommand,
+		reportCommand,
+		shortlogCommand,
+		changelogCommand,
+		silosCommand,
+		blameCommand,
+		auditCommand,
+		setTrackersCommand,
+		setPolicyCommand,
+		setNoreplyEmailsCommand,
+		setRoleTrailersCommand,
+		setCoAuthorOrderCommand,
+		setSignOffCommand,
+		setDevSignOffCommand,
+		setStatsFiltersCommand,
+		mobCommand,
 
 		// Below commands are deprecated.
 		devCommand,
@@ -99,6 +137,40 @@ var showConfigCommand = cli.Command{
 	},
 }
 
+var previewCommand = cli.Command{
+	Name:      "preview",
+	Usage:     "Print the commit message the hooks would write, without committing",
+	ArgsUsage: "[commit-msg-file]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "message, m",
+			Usage: "commit message (default: read from commit-msg-file or stdin)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		msg := c.String("message")
+		if !c.IsSet("message") {
+			var b []byte
+			if file := c.Args().Get(0); file != "" {
+				b, err = ioutil.ReadFile(file)
+			} else {
+				b, err = ioutil.ReadAll(os.Stdin)
+			}
+			if err != nil {
+				return errors.Wrap(err, "read commit msg failed")
+			}
+			msg = string(b)
+		}
+
+		return d.preview(os.Stdout, wd, msg)
+	},
+}
+
 var addInfoCommand = cli.Command{
 	Name:        "add-info",
 	Usage:       "Add xp info to the COMMIT msg file",
@@ -121,11 +193,163 @@ var addInfoCommand = cli.Command{
 	},
 }
 
+var checkMsgCommand = cli.Command{
+	Name:        "check-msg",
+	Usage:       "Validate the COMMIT msg file against the repo policy",
+	Description: "This is supposed to be invoked from inside a commit-msg hook",
+	ArgsUsage:   "commit-msg-file",
+	Hidden:      true,
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		if os.Getenv(noVerifyEnv) != "" {
+			log.Printf("%s is set, skipping policy checks", noVerifyEnv)
+			return nil
+		}
+
+		// The message was already rewritten by the prepare-commit-msg
+		// hook. It is checked as is, so a [..] block which could not be
+		// resolved is still there for RejectPrefix to catch.
+		return d.checkMsg(wd, c.Args().Get(0))
+	},
+}
+
 var addDevCommand = cli.Command{
 	Name:      "add-dev",
 	Usage:     "Add a new developer",
 	ArgsUsage: `id "name" email`,
-	Action:    devAddAction,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "handle",
+			Usage: "GitHub handle (optional)",
+		},
+		cli.IntFlag{
+			Name:  "forge-id",
+			Usage: "numeric GitHub user id, used for the noreply email (optional)",
+		},
+		cli.BoolFlag{
+			Name:  "sign-off",
+			Usage: "add a Signed-off-by for the dev as a co-author in repos requiring sign-offs",
+		},
+	},
+	Action: devAddAction,
+}
+
+var removeDevCommand = cli.Command{
+	Name:      "remove-dev",
+	Usage:     "Remove a developer, along with their team and repo memberships",
+	ArgsUsage: "id",
+	Action: func(c *cli.Context) error {
+		id := c.Args().Get(0)
+		if id == "" {
+			return errors.New("invalid id")
+		}
+
+		if err := d.removeDev(id); err != nil {
+			return errors.Wrap(err, "could not remove dev")
+		}
+
+		return nil
+	},
+}
+
+var addAliasCommand = cli.Command{
+	Name:      "add-alias",
+	Usage:     "Add other ids a developer can be referenced by",
+	ArgsUsage: "id alias1 alias2",
+	Action: func(c *cli.Context) error {
+		args := c.Args()
+		if len(args) < 2 {
+			return errors.New("invalid id/aliases")
+		}
+
+		if err := d.addDevAliases(args[0], args[1:]); err != nil {
+			return errors.Wrap(err, "could not add aliases")
+		}
+
+		return nil
+	},
+}
+
+var addEmailCommand = cli.Command{
+	Name:      "add-email",
+	Usage:     "Add other emails a developer commits with",
+	ArgsUsage: "id email1 email2",
+	Action: func(c *cli.Context) error {
+		args := c.Args()
+		if len(args) < 2 {
+			return errors.New("invalid id/emails")
+		}
+
+		if err := d.addDevEmails(args[0], args[1:]); err != nil {
+			return errors.Wrap(err, "could not add emails")
+		}
+
+		return nil
+	},
+}
+
+var retireDevCommand = cli.Command{
+	Name:      "retire-dev",
+	Usage:     "Stop crediting a developer, while still recognizing them in history",
+	ArgsUsage: "id",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "undo",
+			Usage: "bring a retired developer back",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		id := c.Args().Get(0)
+		if id == "" {
+			return errors.New("invalid id")
+		}
+
+		if err := d.retireDev(id, !c.Bool("undo")); err != nil {
+			return errors.Wrap(err, "could not retire dev")
+		}
+
+		return nil
+	},
+}
+
+var addTeamCommand = cli.Command{
+	Name:      "add-team",
+	Usage:     "Add (or replace) a named team of developers, usable as @name",
+	ArgsUsage: "name dev1 dev2 dev3",
+	Action: func(c *cli.Context) error {
+		args := c.Args()
+		if len(args) < 2 {
+			return errors.New("invalid name/devs")
+		}
+
+		if err := d.addTeam(args[0], args[1:]); err != nil {
+			return errors.Wrap(err, "could not add team")
+		}
+
+		return nil
+	},
+}
+
+var removeTeamCommand = cli.Command{
+	Name:      "remove-team",
+	Usage:     "Remove a named team",
+	ArgsUsage: "name",
+	Action: func(c *cli.Context) error {
+		name := c.Args().Get(0)
+		if name == "" {
+			return errors.New("invalid name")
+		}
+
+		if err := d.removeTeam(name); err != nil {
+			return errors.Wrap(err, "could not remove team")
+		}
+
+		return nil
+	},
 }
 
 var devCommand = cli.Command{
@@ -152,7 +376,25 @@ var devAddAction = func(c *cli.Context) error {
 		return errors.New("invalid id/name/email")
 	}
 
-	d.addDev(id, name, email)
+	if err := d.addDev(id, name, email); err != nil {
+		return errors.Wrap(err, "could not add dev")
+	}
+
+	// An alias given as id updates the dev it belongs to.
+	id = d.canonicalDevID(id)
+
+	if handle := c.String("handle"); handle != "" {
+		if err := d.updateDevHandle(id, handle, c.Int("forge-id")); err != nil {
+			return errors.Wrap(err, "could not set handle")
+		}
+	}
+
+	if c.Bool("sign-off") {
+		if err := d.updateDevSignOff(id, true); err != nil {
+			return errors.Wrap(err, "could not set sign-off")
+		}
+	}
+
 	return nil
 }
 
@@ -179,12 +421,20 @@ var initCommand = cli.Command{
 		},
 		cli.StringSliceFlag{
 			Name:  "devs",
-			Usage: "initial set of devs (optional)",
+			Usage: "initial set of devs or @teams (optional)",
 		},
 		cli.StringFlag{
 			Name:  "story-id",
 			Usage: "story id (optional)",
 		},
+		cli.StringSliceFlag{
+			Name:  "tracker",
+			Usage: "issue tracker: jira, github, linear or custom=<regex> (optional, default: jira and github)",
+		},
+		cli.BoolFlag{
+			Name:  "scope-issue-id",
+			Usage: "take the issue id from the scope of a conventional commit header",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		args := c.Args()
@@ -208,13 +458,33 @@ var initCommand = cli.Command{
 			return errors.Wrap(err, "repo .git hook init failed")
 		}
 
-		devs := c.StringSlice("devs")
+		// Re-running init only changes the settings which are given.
+		var devs []string
+		if c.IsSet("devs") {
+			devs = c.StringSlice("devs")
+		}
 		storyID := c.String("story-id")
 
+		trackers, err := parseTrackers(c.StringSlice("tracker"))
+		if err != nil {
+			return err
+		}
+
 		if err := d.addRepo(dir, devs, storyID); err != nil {
 			return errors.Wrap(err, "could add init repo")
 		}
 
+		if len(trackers) != 0 {
+			if err := d.updateRepoTrackers(dir, trackers); err != nil {
+				return errors.Wrap(err, "could not set trackers")
+			}
+		}
+		if c.IsSet("scope-issue-id") {
+			if err := d.updateRepoScopeIssueID(dir, c.Bool("scope-issue-id")); err != nil {
+				return errors.Wrap(err, "could not set scope issue id")
+			}
+		}
+
 		return nil
 	},
 }
@@ -222,8 +492,18 @@ var initCommand = cli.Command{
 var setDevsCommand = cli.Command{
 	Name:      "set-devs",
 	Usage:     "Set list of devs working on the repo",
-	ArgsUsage: "dev1 dev2 dev3",
-	Action:    repoDevsAction,
+	ArgsUsage: "dev1 dev2 @team",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "driver",
+			Usage: "dev driving (optional)",
+		},
+		cli.StringSliceFlag{
+			Name:  "navigator",
+			Usage: "dev navigating (optional)",
+		},
+	},
+	Action: repoDevsAction,
 }
 
 var repoDevsCommand = cli.Command{
@@ -245,5 +525,693 @@ var repoDevsAction = func(c *cli.Context) error {
 		return errors.Wrap(err, "could not set devs")
 	}
 
+	if err := d.updateRepoRoles(wd, c.String("driver"), c.StringSlice("navigator")); err != nil {
+		return errors.Wrap(err, "could not set roles")
+	}
+
 	return nil
 }
+
+var setTrackersCommand = cli.Command{
+	Name:      "set-trackers",
+	Usage:     "Set issue trackers used to recognize issue ids in the repo",
+	ArgsUsage: "jira|github|linear|custom=<regex> ...",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "scope-issue-id",
+			Usage: "take the issue id from the scope of a conventional commit header (--scope-issue-id=false to stop)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		trackers, err := parseTrackers(c.Args())
+		if err != nil {
+			return err
+		}
+
+		// Without trackers (and without --scope-issue-id) the trackers
+		// go back to the defaults.
+		if len(trackers) != 0 || !c.IsSet("scope-issue-id") {
+			if err := d.updateRepoTrackers(wd, trackers); err != nil {
+				return errors.Wrap(err, "could not set trackers")
+			}
+		}
+		if c.IsSet("scope-issue-id") {
+			if err := d.updateRepoScopeIssueID(wd, c.Bool("scope-issue-id")); err != nil {
+				return errors.Wrap(err, "could not set scope issue id")
+			}
+		}
+
+		return nil
+	},
+}
+
+var setPolicyCommand = cli.Command{
+	Name:  "set-policy",
+	Usage: "Set the rules commit messages in the repo are validated against",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "require-issue-id",
+			Usage: "require an issue id on branches other than the main branches",
+		},
+		cli.StringSliceFlag{
+			Name:  "main-branch",
+			Usage: "branch where an issue id is not required (default: main and master)",
+		},
+		cli.BoolFlag{
+			Name:  "require-co-author",
+			Usage: "require at least one co-author when the repo has devs set",
+		},
+		cli.IntFlag{
+			Name:  "min-subject-length",
+			Usage: "minimum length of the subject line (0 to disable)",
+		},
+		cli.IntFlag{
+			Name:  "max-subject-length",
+			Usage: "maximum length of the subject line (0 to disable)",
+		},
+		cli.BoolFlag{
+			Name:  "reject-prefix",
+			Usage: "reject subjects starting with an unresolved [..] block",
+		},
+		cli.StringSliceFlag{
+			Name:  "conventional-type",
+			Usage: "require a conventional commit header with one of the given types",
+		},
+		cli.BoolFlag{
+			Name:  "clear",
+			Usage: "clear the rules set before applying the given ones",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		// Only the rules which are given are changed.
+		update := func(p *policy) {
+			if c.Bool("clear") {
+				*p = policy{}
+			}
+			if c.IsSet("require-issue-id") {
+				p.RequireIssueID = c.Bool("require-issue-id")
+			}
+			if c.IsSet("main-branch") {
+				p.MainBranches = c.StringSlice("main-branch")
+			}
+			if c.IsSet("require-co-author") {
+				p.RequireCoAuthor = c.Bool("require-co-author")
+			}
+			if c.IsSet("min-subject-length") {
+				p.MinSubjectLength = c.Int("min-subject-length")
+			}
+			if c.IsSet("max-subject-length") {
+				p.MaxSubjectLength = c.Int("max-subject-length")
+			}
+			if c.IsSet("reject-prefix") {
+				p.RejectPrefix = c.Bool("reject-prefix")
+			}
+			if c.IsSet("conventional-type") {
+				p.ConventionalTypes = c.StringSlice("conventional-type")
+			}
+		}
+
+		if err := d.updateRepoPolicy(wd, update); err != nil {
+			return errors.Wrap(err, "could not set policy")
+		}
+
+		return nil
+	},
+}
+
+var setNoreplyEmailsCommand = cli.Command{
+	Name:      "set-noreply-emails",
+	Usage:     "Credit devs with a GitHub handle using their noreply email in the repo",
+	ArgsUsage: "[true|false]",
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		noreply := true
+		if arg := c.Args().Get(0); arg != "" {
+			noreply, err = strconv.ParseBool(arg)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value %s", arg)
+			}
+		}
+
+		if err := d.updateRepoNoreplyEmails(wd, noreply); err != nil {
+			return errors.Wrap(err, "could not set noreply emails")
+		}
+
+		return nil
+	},
+}
+
+var mobCommand = cli.Command{
+	Name:  "mob",
+	Usage: "Mob programming with a rotating driver",
+	Subcommands: []cli.Command{
+		{
+			Name:      "start",
+			Usage:     "Start a mob on a new mob/ branch",
+			ArgsUsage: "dev1 dev2 @team",
+			Flags: []cli.Flag{
+				cli.DurationFlag{
+					Name:  "interval",
+					Value: defaultMobInterval,
+					Usage: "time each driver gets",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				wd, err := os.Getwd()
+				if err != nil {
+					return errors.Wrap(err, "could not get wd")
+				}
+
+				m, err := d.startMob(wd, c.Args(), c.Duration("interval"))
+				if err != nil {
+					return errors.Wrap(err, "could not start mob")
+				}
+
+				fmt.Println(m.status(now()))
+				return nil
+			},
+		},
+		{
+			Name:  "next",
+			Usage: "Commit work in progress and rotate the driver",
+			Action: func(c *cli.Context) error {
+				wd, err := os.Getwd()
+				if err != nil {
+					return errors.Wrap(err, "could not get wd")
+				}
+
+				m, err := d.nextMob(wd)
+				if err != nil {
+					return errors.Wrap(err, "could not rotate driver")
+				}
+
+				fmt.Println(m.status(now()))
+				return nil
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "Print the current driver and the time left in their turn",
+			Action: func(c *cli.Context) error {
+				readOnly = true
+
+				wd, err := os.Getwd()
+				if err != nil {
+					return errors.Wrap(err, "could not get wd")
+				}
+
+				repo, err := d.lookupMob(wd)
+				if err != nil {
+					return err
+				}
+
+				fmt.Println(repo.Mob.status(now()))
+				return nil
+			},
+		},
+		{
+			Name:  "timer",
+			Usage: "Wait until the turn of the current driver is over",
+			Action: func(c *cli.Context) error {
+				readOnly = true
+
+				wd, err := os.Getwd()
+				if err != nil {
+					return errors.Wrap(err, "could not get wd")
+				}
+
+				repo, err := d.lookupMob(wd)
+				if err != nil {
+					return err
+				}
+
+				fmt.Println(repo.Mob.status(now()))
+				time.Sleep(repo.Mob.remaining(now()))
+				fmt.Printf("\atime is up, %s is next (run xp mob next)\n", repo.Mob.nextDriver())
+				return nil
+			},
+		},
+		{
+			Name:  "done",
+			Usage: "Squash the work in progress into a commit crediting the whole mob",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "message of the squashed commit",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				wd, err := os.Getwd()
+				if err != nil {
+					return errors.Wrap(err, "could not get wd")
+				}
+
+				msg := c.String("message")
+				if msg == "" {
+					return errors.New("invalid message")
+				}
+
+				if err := d.doneMob(wd, msg); err != nil {
+					return errors.Wrap(err, "could not finish mob")
+				}
+
+				return nil
+			},
+		},
+	},
+}
+
+var setRoleTrailersCommand = cli.Command{
+	Name:  "set-role-trailers",
+	Usage: "Set the trailer keys used to record driver and navigator roles in the repo",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "driver",
+			Value: defaultRoleTrailers.Driver,
+			Usage: "trailer key for the driver",
+		},
+		cli.StringFlag{
+			Name:  "navigator",
+			Value: defaultRoleTrailers.Navigator,
+			Usage: "trailer key for the navigators",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		keys := &roleTrailers{
+			Driver:    c.String("driver"),
+			Navigator: c.String("navigator"),
+		}
+
+		if err := d.updateRepoRoleTrailers(wd, keys); err != nil {
+			return errors.Wrap(err, "could not set role trailers")
+		}
+
+		return nil
+	},
+}
+
+var setCoAuthorOrderCommand = cli.Command{
+	Name:      "set-co-author-order",
+	Usage:     "Set the order co-authors are written in for the repo",
+	ArgsUsage: strings.Join(coAuthorOrders, "|"),
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		order := c.Args().Get(0)
+		if order == "" {
+			return errors.New("invalid order")
+		}
+
+		if err := d.updateRepoCoAuthorOrder(wd, order); err != nil {
+			return errors.Wrap(err, "could not set co-author order")
+		}
+
+		return nil
+	},
+}
+
+var setSignOffCommand = cli.Command{
+	Name:      "set-sign-off",
+	Usage:     "Add Signed-off-by trailers (DCO) for the author and opted in co-authors in the repo",
+	ArgsUsage: "[true|false]",
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		signOff := true
+		if arg := c.Args().Get(0); arg != "" {
+			signOff, err = strconv.ParseBool(arg)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value %s", arg)
+			}
+		}
+
+		if err := d.updateRepoSignOff(wd, signOff); err != nil {
+			return errors.Wrap(err, "could not set sign-off")
+		}
+
+		return nil
+	},
+}
+
+var setDevSignOffCommand = cli.Command{
+	Name:      "set-dev-sign-off",
+	Usage:     "Opt a developer in (or out) of signing off commits they co-author",
+	ArgsUsage: "id [true|false]",
+	Action: func(c *cli.Context) error {
+		id := c.Args().Get(0)
+		if id == "" {
+			return errors.New("invalid id")
+		}
+
+		signOff := true
+		if arg := c.Args().Get(1); arg != "" {
+			var err error
+			signOff, err = strconv.ParseBool(arg)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value %s", arg)
+			}
+		}
+
+		if err := d.updateDevSignOff(id, signOff); err != nil {
+			return errors.Wrap(err, "could not set sign-off")
+		}
+
+		return nil
+	},
+}
+
+var pairCommand = cli.Command{
+	Name:  "pair",
+	Usage: "Pick the devs working on the repo (and the issue id) interactively",
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		if err := d.pair(wd, os.Stdin, os.Stdout); err != nil {
+			return errors.Wrap(err, "could not pick pair")
+		}
+
+		return nil
+	},
+}
+
+var sinceFlag = cli.StringFlag{
+	Name:  "since",
+	Usage: "only look at commits since, like 30d, 6w, 3m, 1y or 2019-03-04 (default: all history)",
+}
+
+var allReposFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "all-repos",
+		Usage: "look at the history of every repo added to xp, instead of the current one",
+	},
+	cli.IntFlag{
+		Name:  "workers",
+		Value: defaultStatsWorkers,
+		Usage: "number of repos read at a time with --all-repos",
+	},
+}
+
+var statsFlags = append([]cli.Flag{sinceFlag}, allReposFlags...)
+
+var statsCommand = cli.Command{
+	Name:  "stats",
+	Usage: "Reports on who paired with whom, from the commit history",
+	Flags: statsFlags,
+	Action: func(c *cli.Context) error {
+		s, err := d.repoStats(c.String("since"), c.Bool("all-repos"), c.Int("workers"))
+		if err != nil {
+			return errors.Wrap(err, "could not report stats")
+		}
+
+		d.writeStatsSummary(os.Stdout, s)
+
+		return nil
+	},
+	Subcommands: []cli.Command{
+		{
+			Name:  "pairs",
+			Usage: "Print the number of commits shared by each pair of devs",
+			Flags: statsFlags,
+			Action: func(c *cli.Context) error {
+				commits, err := d.statsHistory(c.String("since"), c.Bool("all-repos"), c.Int("workers"))
+				if err != nil {
+					return errors.Wrap(err, "could not report pairs")
+				}
+
+				d.writePairMatrix(os.Stdout, d.matrixStats(commits))
+
+				return nil
+			},
+		},
+		{
+			Name:  "trend",
+			Usage: "Print the share of commits paired on over time, for each dev and team",
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "by",
+					Value: periodWeek,
+					Usage: "period to report by, one of " + strings.Join(trendPeriods, ", "),
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: formatText,
+					Usage: "output format, one of " + strings.Join(trendFormats, ", "),
+				},
+				cli.StringFlag{
+					Name:  "since",
+					Value: defaultTrendSince,
+					Usage: "only look at commits since, like 30d, 6w, 3m, 1y, 2019-03-04 or all",
+				},
+			}, allReposFlags...),
+			Action: func(c *cli.Context) error {
+				err := d.repoTrend(os.Stdout, c.String("since"), c.Bool("all-repos"), c.Int("workers"), c.String("by"), c.String("format"))
+				if err != nil {
+					return errors.Wrap(err, "could not report trend")
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "time",
+			Usage: "Estimate the hours each pair of devs spent together, from sessions of commits",
+			Flags: append([]cli.Flag{
+				cli.DurationFlag{
+					Name:  "gap",
+					Value: defaultSessionGap,
+					Usage: "longest time between commits of the same session",
+				},
+			}, statsFlags...),
+			Action: func(c *cli.Context) error {
+				err := d.repoPairTime(os.Stdout, c.String("since"), c.Bool("all-repos"), c.Int("workers"), c.Duration("gap"))
+				if err != nil {
+					return errors.Wrap(err, "could not report time")
+				}
+				return nil
+			},
+		},
+	},
+}
+
+var blameCommand = cli.Command{
+	Name:      "blame",
+	Usage:     "Show who last changed each line of a file, along with the co-authors",
+	ArgsUsage: "file",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "L",
+			Usage: "only blame the line range, like git blame -L 10,20 (can be repeated)",
+		},
+		cli.BoolFlag{
+			Name:  "porcelain",
+			Usage: "print git blame --porcelain output, with a co-authors header for each commit",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		file := c.Args().Get(0)
+		if file == "" {
+			return errors.New("invalid file")
+		}
+
+		if err := d.repoBlame(os.Stdout, file, c.StringSlice("L"), c.Bool("porcelain")); err != nil {
+			return errors.Wrap(err, "could not blame")
+		}
+
+		return nil
+	},
+}
+
+var auditCommand = cli.Command{
+	Name:      "audit",
+	Usage:     "Check the commits in a range for missing co-authors, unknown issue ids or co-authors and unresolved prefixes",
+	ArgsUsage: "revision-range",
+	Flags: []cli.Flag{
+		cli.DurationFlag{
+			Name:  "gap",
+			Value: defaultSessionGap,
+			Usage: "longest time between commits of the same pairing session",
+		},
+		cli.StringSliceFlag{
+			Name:  "ignore",
+			Usage: "kind of problem to leave out, one of " + strings.Join(auditKinds, ", ") + " (can be repeated)",
+		},
+		cli.StringSliceFlag{
+			Name:  "warn",
+			Usage: "kind of problem to report without failing, one of " + strings.Join(auditKinds, ", ") + " (can be repeated)",
+		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "print as JSON",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err, "could not get wd")
+		}
+
+		err = d.repoAudit(os.Stdout, wd, c.Args().Get(0), c.Duration("gap"), c.StringSlice("ignore"), c.StringSlice("warn"), c.Bool("json"))
+		if err != nil {
+			return errors.Wrap(err, "audit failed")
+		}
+
+		return nil
+	},
+}
+
+var reportCommand = cli.Command{
+	Name:  "report",
+	Usage: "Write the pair matrix, pairing trend and issues to a self-contained HTML report",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "html",
+			Usage: "directory to write the report to",
+		},
+		cli.StringFlag{
+			Name:  "by",
+			Value: periodWeek,
+			Usage: "period to show the trend by, one of " + strings.Join(trendPeriods, ", "),
+		},
+	}, statsFlags...),
+	Action: func(c *cli.Context) error {
+		path, err := d.repoReport(c.String("html"), c.String("since"), c.Bool("all-repos"), c.Int("workers"), c.String("by"))
+		if err != nil {
+			return errors.Wrap(err, "could not write report")
+		}
+
+		fmt.Println("wrote", path)
+
+		return nil
+	},
+}
+
+var setStatsFiltersCommand = cli.Command{
+	Name:  "set-stats-filters",
+	Usage: "Set which commits the stats leave out (bots and merges by default)",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "exclude-author",
+			Usage: "leave out commits whose `Name <email>` author matches the regexp (can be repeated)",
+		},
+		cli.BoolFlag{
+			Name:  "include-bots",
+			Usage: "count the commits of [bot] authors",
+		},
+		cli.BoolFlag{
+			Name:  "include-merges",
+			Usage: "count merge commits",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		err := d.updateStatsFilters(&statsFilters{
+			ExcludeAuthors: c.StringSlice("exclude-author"),
+			IncludeBots:    c.Bool("include-bots"),
+			IncludeMerges:  c.Bool("include-merges"),
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not set stats filters")
+		}
+		return nil
+	},
+}
+
+var shortlogCommand = cli.Command{
+	Name:      "shortlog",
+	Usage:     "Summarize the commits credited to each dev, as author or co-author",
+	ArgsUsage: "[revision-range]",
+	Flags: []cli.Flag{
+		sinceFlag,
+		cli.StringFlag{
+			Name:  "until",
+			Usage: "only look at commits until, like 30d, 6w, 3m, 1y or 2019-03-04",
+		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "print as JSON",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if err := d.repoShortlog(os.Stdout, c.Args(), c.String("since"), c.String("until"), c.Bool("json")); err != nil {
+			return errors.Wrap(err, "could not summarize")
+		}
+		return nil
+	},
+}
+
+var changelogCommand = cli.Command{
+	Name:      "changelog",
+	Usage:     "List the commits in a range grouped by issue id",
+	ArgsUsage: "from..to",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: formatMarkdown,
+			Usage: "output format: markdown, json or text",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		revRange := c.Args().Get(0)
+		if revRange == "" {
+			return errors.New("invalid range")
+		}
+
+		if err := d.repoChangelog(os.Stdout, revRange, c.String("format")); err != nil {
+			return errors.Wrap(err, "could not write changelog")
+		}
+		return nil
+	},
+}
+
+var silosCommand = cli.Command{
+	Name:  "silos",
+	Usage: "List the directories only one dev (or pair) worked on recently",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "months",
+			Value: defaultSiloMonths,
+			Usage: "look at the commits of the last months",
+		},
+		cli.IntFlag{
+			Name:  "depth",
+			Value: defaultSiloDepth,
+			Usage: "how many levels of directories to report on",
+		},
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "list every directory, not just the silos",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if err := d.repoSilos(os.Stdout, c.Int("months"), c.Int("depth"), c.Bool("all")); err != nil {
+			return errors.Wrap(err, "could not find silos")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// conventionalRegexp matches a Conventional Commits header such as
+// `feat(api)!: ` at the start of a message. Types are lowercase, so
+// subjects like `WIP: ` or `Note: ` are not taken for one.
+var conventionalRegexp = regexp.MustCompile(`^([a-z]+)(?:\(([^()\n]*)\))?!?: `)
+
+type conventionalHeader struct {
+	raw   string
+	typ   string
+	scope string
+}
+
+// parseConventionalHeader returns nil if the message does not start with
+// a Conventional Commits header.
+func parseConventionalHeader(msg string) *conventionalHeader {
+	m := conventionalRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	return &conventionalHeader{raw: m[0], typ: m[1], scope: m[2]}
+}
+
+// splitConventionalHeader splits the message into the Conventional Commits
+// header (if any) and the rest of the message, which is where xp looks
+// for the ids block in that case.
+func splitConventionalHeader(msg string) (string, string) {
+	h := parseConventionalHeader(msg)
+	if h == nil {
+		return "", msg
+	}
+	return h.raw, msg[len(h.raw):]
+}
+
+// checkConventionalType returns a violation if the subject is not a
+// Conventional Commits header with one of the given types.
+func checkConventionalType(subject string, types []string) string {
+	h := parseConventionalHeader(subject)
+	if h == nil {
+		return fmt.Sprintf("subject is not a conventional commit header (types: %s)", strings.Join(types, ", "))
+	}
+
+	for _, t := range types {
+		if t == h.typ {
+			return ""
+		}
+	}
+	return fmt.Sprintf("type %s is not one of %s", h.typ, strings.Join(types, ", "))
+}
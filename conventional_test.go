@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConventionalHeader(t *testing.T) {
+	tests := []struct {
+		msg    string
+		header *conventionalHeader
+	}{
+		{
+			msg:    "feat: hello",
+			header: &conventionalHeader{raw: "feat: ", typ: "feat"},
+		},
+		{
+			msg:    "feat(api): hello",
+			header: &conventionalHeader{raw: "feat(api): ", typ: "feat", scope: "api"},
+		},
+		{
+			msg:    "fix(GOJ-1)!: hello",
+			header: &conventionalHeader{raw: "fix(GOJ-1)!: ", typ: "fix", scope: "GOJ-1"},
+		},
+		{
+			msg: "[ak] feat: hello",
+		},
+		{
+			msg: "Fix: the thing",
+		},
+		{
+			msg: "WIP: [ak] fix",
+		},
+		{
+			msg: "Hello there",
+		},
+		{
+			msg: "feat(api):hello",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.header, parseConventionalHeader(tt.msg), "msg %s", tt.msg)
+	}
+}
+
+func TestSplitConventionalHeader(t *testing.T) {
+	header, rest := splitConventionalHeader("feat(api): [ak] hello")
+	assert.Equal(t, "feat(api): ", header)
+	assert.Equal(t, "[ak] hello", rest)
+
+	header, rest = splitConventionalHeader("Note: [ak] hello")
+	assert.Equal(t, "", header)
+	assert.Equal(t, "Note: [ak] hello", rest)
+
+	header, rest = splitConventionalHeader("[ak] hello")
+	assert.Equal(t, "", header)
+	assert.Equal(t, "[ak] hello", rest)
+}
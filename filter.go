@@ -0,0 +1,90 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// statsFilters decide which commits the stats leave out.
+type statsFilters struct {
+	// ExcludeAuthors are patterns matched against the `Name <email>` of
+	// the commit authors to leave out.
+	ExcludeAuthors []string `json:"excludeAuthors,omitempty"`
+
+	// IncludeBots counts the commits of bots, which are left out by
+	// default.
+	IncludeBots bool `json:"includeBots,omitempty"`
+
+	// IncludeMerges counts merge commits, which are left out by default.
+	IncludeMerges bool `json:"includeMerges,omitempty"`
+}
+
+// botAuthor matches the authors of commits made by bots, like
+// dependabot[bot].
+const botAuthor = `\[bot\]`
+
+// commitFilter is the compiled form of statsFilters.
+type commitFilter struct {
+	excludeAuthors []*regexp.Regexp
+	merges         bool
+}
+
+// compile builds the filter, with the defaults for a nil statsFilters.
+func (f *statsFilters) compile() (*commitFilter, error) {
+	if f == nil {
+		f = &statsFilters{}
+	}
+
+	patterns := f.ExcludeAuthors
+	if !f.IncludeBots {
+		patterns = append([]string{botAuthor}, patterns...)
+	}
+
+	filter := &commitFilter{merges: f.IncludeMerges}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid author filter %s", p)
+		}
+		filter.excludeAuthors = append(filter.excludeAuthors, re)
+	}
+	return filter, nil
+}
+
+// keep tells if c is counted in the stats.
+func (f *commitFilter) keep(c *commit) bool {
+	if f == nil {
+		return true
+	}
+	for _, re := range f.excludeAuthors {
+		if re.MatchString(c.author.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the commits to be counted in the stats.
+func (f *commitFilter) apply(commits []*commit) []*commit {
+	var kept []*commit
+	for _, c := range commits {
+		if f.keep(c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func (d *data) updateStatsFilters(f *statsFilters) error {
+	if _, err := f.compile(); err != nil {
+		return err
+	}
+
+	if f.ExcludeAuthors == nil && !f.IncludeBots && !f.IncludeMerges {
+		f = nil
+	}
+	d.StatsFilters = f
+
+	return nil
+}
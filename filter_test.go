@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitFilter(t *testing.T) {
+	commits := []*commit{
+		{hash: "a1", author: &dev{Name: "Karan Misra", Email: "karan@beef.com"}},
+		{hash: "b2", author: &dev{Name: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com"}},
+		{hash: "c3", author: &dev{Name: "CI", Email: "ci@beef.com"}},
+	}
+
+	hashes := func(commits []*commit) []string {
+		var hashes []string
+		for _, c := range commits {
+			hashes = append(hashes, c.hash)
+		}
+		return hashes
+	}
+
+	tests := []struct {
+		filters *statsFilters
+		hashes  []string
+		merges  bool
+		errMsg  string
+	}{
+		{
+			hashes: []string{"a1", "c3"},
+		},
+		{
+			filters: &statsFilters{ExcludeAuthors: []string{"<ci@"}, IncludeMerges: true},
+			hashes:  []string{"a1"},
+			merges:  true,
+		},
+		{
+			filters: &statsFilters{IncludeBots: true},
+			hashes:  []string{"a1", "b2", "c3"},
+		},
+		{
+			filters: &statsFilters{ExcludeAuthors: []string{"("}},
+			errMsg:  "invalid author filter (: error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for _, tt := range tests {
+		filter, err := tt.filters.compile()
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.hashes, hashes(filter.apply(commits)))
+			assert.Equal(t, tt.merges, filter.merges)
+		}
+	}
+}
+
+func TestDataUpdateStatsFilters(t *testing.T) {
+	d := data{}
+
+	assert.NoError(t, d.updateStatsFilters(&statsFilters{IncludeMerges: true}))
+	assert.Equal(t, &statsFilters{IncludeMerges: true}, d.StatsFilters)
+
+	assert.NoError(t, d.updateStatsFilters(&statsFilters{}))
+	assert.Nil(t, d.StatsFilters)
+
+	assert.Error(t, d.updateStatsFilters(&statsFilters{ExcludeAuthors: []string{"["}}))
+	assert.Nil(t, d.StatsFilters)
+}
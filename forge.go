@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const noreplyDomain = "users.noreply.github.com"
+
+// noreplyEmail returns the GitHub noreply email of the dev, or an empty
+// string if the dev has no handle.
+func (d *dev) noreplyEmail() string {
+	if d.Handle == "" {
+		return ""
+	}
+	if d.ForgeID != 0 {
+		return fmt.Sprintf("%d+%s@%s", d.ForgeID, d.Handle, noreplyDomain)
+	}
+	return d.Handle + "@" + noreplyDomain
+}
+
+// noreplyDev returns the dev as credited with their noreply email, or the
+// dev itself if they have no handle.
+func noreplyDev(d *dev) *dev {
+	if d == nil || d.Handle == "" {
+		return d
+	}
+	return &dev{Name: d.Name, Email: d.noreplyEmail()}
+}
+
+// lookupDevByHandle finds the dev with the given GitHub handle. Handles
+// are case insensitive.
+func (d *data) lookupDevByHandle(handle string) (string, *dev) {
+	for id, dev := range d.Devs {
+		if dev.Handle != "" && strings.EqualFold(dev.Handle, handle) {
+			return id, dev
+		}
+	}
+	return "", nil
+}
+
+func (d *data) updateDevHandle(id, handle string, forgeID int) error {
+	dev := d.lookupDev(id)
+	if dev == nil {
+		return errors.Errorf("no dev with id %s found", id)
+	}
+
+	handle = strings.TrimPrefix(handle, "@")
+	if handle != "" {
+		if otherID, _ := d.lookupDevByHandle(handle); otherID != "" && otherID != id {
+			return errors.Errorf("handle %s is already used by dev %s", handle, otherID)
+		}
+		if _, ok := d.lookupTeam(teamPrefix + handle); ok {
+			return errors.Errorf("handle %s clashes with the name of a team", handle)
+		}
+	}
+
+	dev.Handle = handle
+	dev.ForgeID = forgeID
+
+	return nil
+}
+
+func (d *data) updateRepoNoreplyEmails(wd string, noreply bool) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	repo.NoreplyEmails = noreply
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevNoreplyEmail(t *testing.T) {
+	tests := []struct {
+		dev   dev
+		email string
+	}{
+		{
+			dev:   dev{Name: "akshat", Email: "akshat@beef.com"},
+			email: "",
+		},
+		{
+			dev:   dev{Name: "akshat", Email: "akshat@beef.com", Handle: "akshat"},
+			email: "akshat@users.noreply.github.com",
+		},
+		{
+			dev:   dev{Name: "akshat", Email: "akshat@beef.com", Handle: "akshat", ForgeID: 1234},
+			email: "1234+akshat@users.noreply.github.com",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.email, tt.dev.noreplyEmail())
+	}
+}
+
+func TestDataResolveDevRef(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"ak": &dev{Name: "akshat", Email: "akshat@beef.com", Handle: "AkshatS"},
+			"km": &dev{Name: "Karan Misra", Email: "karan@beef.com"},
+		},
+	}
+
+	assert.Equal(t, "ak", d.resolveDevRef("ak"))
+	assert.Equal(t, "ak", d.resolveDevRef("@akshats"))
+	assert.Equal(t, "km", d.resolveDevRef("km"))
+	assert.Equal(t, "", d.resolveDevRef("@km"))
+	assert.Equal(t, "", d.resolveDevRef("anand"))
+}
+
+func TestDataUpdateDevHandle(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"ak": &dev{Name: "akshat", Email: "akshat@beef.com"},
+			"km": &dev{Name: "Karan Misra", Email: "karan@beef.com", Handle: "kidoman"},
+		},
+	}
+
+	assert.NoError(t, d.updateDevHandle("ak", "@akshat", 1234))
+	assert.Equal(t, &dev{Name: "akshat", Email: "akshat@beef.com", Handle: "akshat", ForgeID: 1234}, d.Devs["ak"])
+
+	assert.NoError(t, d.updateDevHandle("km", "kidoman", 42))
+	assert.Equal(t, 42, d.Devs["km"].ForgeID)
+
+	err := d.updateDevHandle("ak", "Kidoman", 0)
+	if assert.Error(t, err) {
+		assert.Equal(t, "handle Kidoman is already used by dev km", err.Error())
+	}
+
+	err = d.updateDevHandle("anand", "anand", 0)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no dev with id anand found", err.Error())
+	}
+}
+
+func TestUpdateRepoNoreplyEmails(t *testing.T) {
+	d := data{
+		Repos: map[string]*repo{
+			"/a": new(repo),
+		},
+	}
+
+	assert.NoError(t, d.updateRepoNoreplyEmails("/a", true))
+	assert.True(t, d.Repos["/a"].NoreplyEmails)
+
+	err := d.updateRepoNoreplyEmails("/b", true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no repo with path /b found", err.Error())
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// commit is a commit read from the history of a repo, along with the xp
+// info found in its message.
+type commit struct {
+	hash      string
+	author    *dev
+	date      time.Time
+	msg       string
+	coAuthors []*dev
+
+	// files are the files changed, when asked for with --name-only.
+	files []string
+}
+
+// Separators of the fields and records in the git log output, which are
+// unlikely to show up in commit messages.
+const (
+	historyFieldSep  = "\x1f"
+	historyRecordSep = "\x1e"
+	historyFormat    = "--format=" + historyRecordSep + "%H" + historyFieldSep + "%aN" + historyFieldSep + "%aE" + historyFieldSep + "%at" + historyFieldSep + "%B" + historyFieldSep
+)
+
+// readHistory reads the commits of the repo at repoPath (the current
+// repo if empty), newest first. args are passed on to git log. Authors
+// and co-authors are mapped through the .mailmap of the repo.
+func readHistory(repoPath string, args ...string) ([]*commit, error) {
+	var gitArgs []string
+	if repoPath != "" {
+		gitArgs = append(gitArgs, "-C", repoPath)
+	}
+	gitArgs = append(gitArgs, "log", historyFormat)
+	gitArgs = append(gitArgs, args...)
+
+	output, err := gitRun(gitArgs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "read history failed")
+	}
+
+	commits, err := parseHistory(output)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyMailmap(repoPath, commits); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+func parseHistory(output string) ([]*commit, error) {
+	var commits []*commit
+	for _, record := range strings.Split(output, historyRecordSep) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		// The files changed come after the last field with --name-only.
+		fields := strings.SplitN(record, historyFieldSep, 6)
+		if len(fields) != 6 {
+			return nil, errors.Errorf("unexpected git log record %q", record)
+		}
+
+		ts, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid date of commit %s", fields[0])
+		}
+
+		var files []string
+		for _, file := range strings.Split(fields[5], "\n") {
+			if file = strings.TrimSpace(file); file != "" {
+				files = append(files, file)
+			}
+		}
+
+		msg := strings.TrimSpace(fields[4])
+		commits = append(commits, &commit{
+			hash:      fields[0],
+			author:    &dev{Name: fields[1], Email: fields[2]},
+			date:      time.Unix(ts, 0),
+			msg:       msg,
+			coAuthors: existingDevs(msg),
+			files:     files,
+		})
+	}
+	return commits, nil
+}
+
+// contributorID is the roster id of the dev using email, or the email
+// itself for devs missing from the roster.
+func (d *data) contributorID(email string) string {
+	if id, _ := d.lookupDevByEmail(email); id != "" {
+		return id
+	}
+	return emailKey(email)
+}
+
+// contributors are the ids of the author and co-authors of c, sorted.
+func (d *data) contributors(c *commit) []string {
+	ids := []string{d.contributorID(c.author.Email)}
+	for _, dev := range c.coAuthors {
+		if id := d.contributorID(dev.Email); !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// parseTime parses a point in history, either relative to now like 30d,
+// 6w, 3m (months) and 1y, or a date like 2019-03-04.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+
+	if len(s) < 2 {
+		return time.Time{}, errors.Errorf("invalid time %q", s)
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, errors.Errorf("invalid time %q", s)
+	}
+
+	t := now()
+	switch s[len(s)-1] {
+	case 'd':
+		return t.AddDate(0, 0, -n), nil
+	case 'w':
+		return t.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return t.AddDate(0, -n, 0), nil
+	case 'y':
+		return t.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, errors.Errorf("invalid time %q, expected like 30d, 6w, 3m, 1y or 2019-03-04", s)
+}
+
+// allHistory can be given as since to look at all history, when a command
+// defaults to a bounded window.
+const allHistory = "all"
+
+// historyArgs are the git log arguments for the commits to report on:
+// between since and until when given, merges left out unless asked for.
+func historyArgs(since, until string, merges bool) ([]string, error) {
+	var args []string
+	if !merges {
+		args = append(args, "--no-merges")
+	}
+	if since != "" && since != allHistory {
+		t, err := parseTime(since)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--since="+t.Format(time.RFC3339))
+	}
+	if until != "" {
+		t, err := parseTime(until)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--until="+t.Format(time.RFC3339))
+	}
+	return args, nil
+}
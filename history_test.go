@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logRecord renders a commit the way readHistory asks git log to, with
+// the files changed as listed by --name-only.
+func logRecord(hash, author string, ts int64, msg string, files ...string) string {
+	name, email := nameEmail(author)
+	record := historyRecordSep + strings.Join([]string{hash, name, email, strconv.FormatInt(ts, 10), msg}, historyFieldSep) + historyFieldSep + "\n"
+	if len(files) != 0 {
+		record += "\n" + strings.Join(files, "\n") + "\n"
+	}
+	return record
+}
+
+func TestReadHistory(t *testing.T) {
+	output := logRecord("b2", "Karan Misra <karan@beef.com>", 1551700000,
+		"Line 2\n\nCo-authored-by: A <anand@old.com>\nCo-authored-by: broken\n") +
+		logRecord("a1", "Anand Shankar <anand@beef.com>", 1551600000, "Line 1\n", "a/b.go", "c.go")
+
+	calls, restore := stubGit(map[string]string{
+		"log":           output,
+		"check-mailmap": "Anand Shankar <anand@beef.com>",
+	}, "")
+	defer restore()
+
+	commits, err := readHistory("/a", "--no-merges")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{
+		"-C /a log " + historyFormat + " --no-merges",
+		"-C /a check-mailmap A <anand@old.com>",
+	}, *calls)
+	assert.Equal(t, []*commit{
+		{
+			hash:      "b2",
+			author:    &dev{Name: "Karan Misra", Email: "karan@beef.com"},
+			date:      time.Unix(1551700000, 0),
+			msg:       "Line 2\n\nCo-authored-by: A <anand@old.com>\nCo-authored-by: broken",
+			coAuthors: []*dev{{Name: "Anand Shankar", Email: "anand@beef.com"}},
+		},
+		{
+			hash:   "a1",
+			author: &dev{Name: "Anand Shankar", Email: "anand@beef.com"},
+			date:   time.Unix(1551600000, 0),
+			msg:    "Line 1",
+			files:  []string{"a/b.go", "c.go"},
+		},
+	}, commits)
+
+	_, err = parseHistory(historyRecordSep + "a1" + historyFieldSep + "Karan")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unexpected git log record")
+	}
+}
+
+func TestContributors(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan": {Name: "Karan Misra", Email: "karan@beef.com"},
+			"priya": {Name: "Priya Rao", Email: "priya@beef.com", Handle: "priyar", ForgeID: 1234},
+		},
+	}
+
+	c := &commit{
+		author: &dev{Name: "Karan", Email: "Karan+work@beef.com"},
+		coAuthors: []*dev{
+			{Name: "Priya Rao", Email: "1234+priyar@users.noreply.github.com"},
+			{Name: "Karan Misra", Email: "karan@beef.com"},
+			{Name: "Someone", Email: "Someone@Else.com"},
+		},
+	}
+
+	assert.Equal(t, []string{"karan", "priya", "someone@else.com"}, d.contributors(c))
+}
+
+func TestParseTime(t *testing.T) {
+	_, restore := stubGit(nil, "")
+	defer restore()
+
+	tests := []struct {
+		since  string
+		t      time.Time
+		errMsg string
+	}{
+		{since: "30d", t: time.Date(2019, 2, 2, 10, 0, 0, 0, time.UTC)},
+		{since: "2w", t: time.Date(2019, 2, 18, 10, 0, 0, 0, time.UTC)},
+		{since: "3m", t: time.Date(2018, 12, 4, 10, 0, 0, 0, time.UTC)},
+		{since: "1y", t: time.Date(2018, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{since: "2019-01-15", t: time.Date(2019, 1, 15, 0, 0, 0, 0, time.Local)},
+		{since: "d", errMsg: `invalid time "d"`},
+		{since: "-3d", errMsg: `invalid time "-3d"`},
+		{since: "3h", errMsg: `invalid time "3h", expected like 30d, 6w, 3m, 1y or 2019-03-04`},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTime(tt.since)
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.True(t, tt.t.Equal(got), "since %s: %s", tt.since, got)
+		}
+	}
+}
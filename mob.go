@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// mob is the state of a mob programming session in a repo. The session
+// happens on its own branch, with the driver rotating through Devs.
+type mob struct {
+	Devs          []string  `json:"devs"`
+	Driver        int       `json:"driver"`
+	Interval      string    `json:"interval"`
+	TurnStartedAt time.Time `json:"turnStartedAt"`
+
+	Branch     string `json:"branch"`
+	BaseBranch string `json:"baseBranch"`
+	BaseCommit string `json:"baseCommit"`
+
+	// Squashed is set once the WIP commits are squashed, so retrying a
+	// done which failed later on does not squash again.
+	Squashed bool `json:"squashed,omitempty"`
+}
+
+const (
+	mobBranchPrefix    = "mob/"
+	defaultMobInterval = 10 * time.Minute
+)
+
+var now = time.Now
+
+func (m *mob) interval() time.Duration {
+	interval, err := time.ParseDuration(m.Interval)
+	if err != nil || interval <= 0 {
+		return defaultMobInterval
+	}
+	return interval
+}
+
+func (m *mob) driver() string {
+	return m.Devs[m.Driver%len(m.Devs)]
+}
+
+func (m *mob) nextDriver() string {
+	return m.Devs[(m.Driver+1)%len(m.Devs)]
+}
+
+func (m *mob) rotate(t time.Time) {
+	m.Driver = (m.Driver + 1) % len(m.Devs)
+	m.TurnStartedAt = t
+}
+
+// remaining is the time left in the turn of the current driver. It is
+// negative once the turn is over.
+func (m *mob) remaining(t time.Time) time.Duration {
+	return m.TurnStartedAt.Add(m.interval()).Sub(t)
+}
+
+func (m *mob) status(t time.Time) string {
+	remaining := m.remaining(t).Round(time.Second)
+	if remaining <= 0 {
+		return fmt.Sprintf("driver: %s (turn over %s ago, %s is next)", m.driver(), -remaining, m.nextDriver())
+	}
+	return fmt.Sprintf("driver: %s (%s left, %s is next)", m.driver(), remaining, m.nextDriver())
+}
+
+// removeDev takes the dev out of the rotation, keeping the current driver
+// unless it is the dev removed.
+func (m *mob) removeDev(id string) {
+	for i := 0; i < len(m.Devs); i++ {
+		if m.Devs[i] != id {
+			continue
+		}
+		m.Devs = append(m.Devs[:i:i], m.Devs[i+1:]...)
+		if i < m.Driver {
+			m.Driver--
+		}
+		i--
+	}
+	if len(m.Devs) != 0 {
+		m.Driver %= len(m.Devs)
+	} else {
+		m.Driver = 0
+	}
+}
+
+// sessionDevs are the devs currently working on the repo: the mob if one
+// is in progress, the repo devs otherwise.
+func (r *repo) sessionDevs() []string {
+	if r.Mob != nil {
+		return r.Mob.Devs
+	}
+	return r.Devs
+}
+
+func (d *data) lookupMob(wd string) (*repo, error) {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return nil, errors.Errorf("no repo with path %s found", wd)
+	}
+	if repo.Mob == nil {
+		return nil, errors.New("no mob in progress")
+	}
+	return repo, nil
+}
+
+func (d *data) startMob(wd string, devIDs []string, interval time.Duration) (*mob, error) {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return nil, errors.Errorf("no repo with path %s found", wd)
+	}
+	if repo.Mob != nil {
+		return nil, errors.Errorf("mob already in progress on branch %s", repo.Mob.Branch)
+	}
+
+	if err := d.validateDevs(devIDs); err != nil {
+		return nil, errors.Wrap(err, "dev ids validation failed")
+	}
+	devIDs = d.expandDevIDs(devIDs)
+	if len(devIDs) < 2 {
+		return nil, errors.New("a mob needs at least 2 devs")
+	}
+
+	baseBranch, err := gitBranch()
+	if err != nil {
+		return nil, errors.Wrap(err, "get current branch failed")
+	}
+	baseCommit, err := gitRun("rev-parse", "HEAD")
+	if err != nil {
+		return nil, errors.Wrap(err, "get current commit failed")
+	}
+
+	branch := mobBranchPrefix + baseBranch
+	if _, err := gitRun("checkout", "-b", branch); err != nil {
+		return nil, errors.Wrapf(err, "create branch %s failed", branch)
+	}
+
+	repo.Mob = &mob{
+		Devs:          devIDs,
+		Interval:      interval.String(),
+		TurnStartedAt: now(),
+		Branch:        branch,
+		BaseBranch:    baseBranch,
+		BaseCommit:    baseCommit,
+	}
+
+	return repo.Mob, nil
+}
+
+// commitWIP commits any pending changes of the current driver to the mob
+// branch. The commit-msg hook is skipped as WIP commits are not meant to
+// pass the repo policy.
+func (m *mob) commitWIP() error {
+	status, err := gitRun("status", "--porcelain")
+	if err != nil {
+		return errors.Wrap(err, "get status failed")
+	}
+	if status == "" {
+		return nil
+	}
+
+	if _, err := gitRun("add", "--all"); err != nil {
+		return errors.Wrap(err, "add changes failed")
+	}
+	if _, err := gitRun("commit", "--no-verify", "-m", "mob: wip by "+m.driver()); err != nil {
+		return errors.Wrap(err, "commit wip failed")
+	}
+
+	return nil
+}
+
+func (d *data) nextMob(wd string) (*mob, error) {
+	repo, err := d.lookupMob(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.Mob.commitWIP(); err != nil {
+		return nil, err
+	}
+
+	repo.Mob.rotate(now())
+
+	return repo.Mob, nil
+}
+
+// doneMob squashes the WIP commits of the mob into a single commit which
+// credits everyone who was part of the mob, and brings it into the
+// branch the mob started from.
+func (d *data) doneMob(wd, msg string) error {
+	repo, err := d.lookupMob(wd)
+	if err != nil {
+		return err
+	}
+	m := repo.Mob
+
+	if !m.Squashed {
+		if err := d.squashMob(m, msg); err != nil {
+			return err
+		}
+		m.Squashed = true
+	}
+
+	if _, err := gitRun("checkout", m.BaseBranch); err != nil {
+		return errors.Wrapf(err, "checkout %s failed", m.BaseBranch)
+	}
+	if _, err := gitRun("merge", "--ff-only", m.Branch); err != nil {
+		return errors.Wrapf(err, "%s could not be fast-forwarded, merge %s manually", m.BaseBranch, m.Branch)
+	}
+	if _, err := gitRun("branch", "-D", m.Branch); err != nil {
+		return errors.Wrapf(err, "delete branch %s failed", m.Branch)
+	}
+
+	// The mob is only over once it is merged, so a failure above can be
+	// retried.
+	repo.Mob = nil
+
+	return nil
+}
+
+// squashMob commits the pending changes and squashes the WIP commits of the
+// mob into a single commit with msg.
+func (d *data) squashMob(m *mob, msg string) error {
+	if err := m.commitWIP(); err != nil {
+		return err
+	}
+
+	wipMsgs, err := gitRun("log", "--format=%B", m.BaseCommit+"..HEAD")
+	if err != nil {
+		return errors.Wrap(err, "read wip commits failed")
+	}
+	if wipMsgs == "" {
+		return nil
+	}
+
+	if _, err := gitRun("reset", "--soft", m.BaseCommit); err != nil {
+		return errors.Wrap(err, "squash wip commits failed")
+	}
+	if _, err := gitRun("commit", "-m", d.mobMsg(m, msg, wipMsgs)); err != nil {
+		return errors.Wrap(err, "commit squashed wip failed")
+	}
+	return nil
+}
+
+// mobMsg builds the message of the squashed commit, crediting the mob
+// as well as the co-authors already credited in the WIP commits.
+func (d *data) mobMsg(m *mob, msg, wipMsgs string) string {
+	var devs []*dev
+	seen := make(map[string]bool)
+
+	for _, devID := range m.Devs {
+		if dev := d.lookupDev(devID); dev != nil {
+			devs = append(devs, dev)
+			seen[emailKey(dev.Email)] = true
+		}
+	}
+	for _, dev := range existingDevs(wipMsgs) {
+		if !seen[emailKey(dev.Email)] {
+			devs = append(devs, dev)
+			seen[emailKey(dev.Email)] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(msg))
+	b.WriteString("\n\n")
+	for _, dev := range devs {
+		fmt.Fprintf(&b, "Co-authored-by: %s <%s>\n", dev.Name, dev.Email)
+	}
+	return b.String()
+}
+
+var gitRun = func(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrap(err, "git exec failed")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
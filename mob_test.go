@@ -0,0 +1,324 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMobTestData() *data {
+	return &data{
+		Devs: map[string]*dev{
+			"ak": &dev{Name: "akshat", Email: "akshat@beef.com"},
+			"km": &dev{Name: "Karan Misra", Email: "karan@beef.com"},
+			"an": &dev{Name: "Anand Shankar", Email: "anand@beef.com"},
+		},
+		Repos: map[string]*repo{
+			"/a": &repo{Devs: []string{"ak"}},
+		},
+		Teams: map[string][]string{
+			"payments": {"km", "an"},
+		},
+	}
+}
+
+// stubGit replaces gitRun with a func recording the invocations, and
+// answering with the given outputs keyed by the git subcommand.
+func stubGit(outputs map[string]string, failing string) (*[]string, func()) {
+	oldGitRun, oldGitBranch, oldNow := gitRun, gitBranch, now
+
+	var calls []string
+	gitRun = func(args ...string) (string, error) {
+		call := strings.Join(args, " ")
+		calls = append(calls, call)
+		if failing != "" && strings.HasPrefix(call, failing) {
+			return "", errors.New("failed")
+		}
+		// Outputs are by git command, also when run with -C path.
+		if args[0] == "-C" {
+			return outputs[args[2]], nil
+		}
+		return outputs[args[0]], nil
+	}
+	gitBranch = func() (string, error) {
+		return "master", nil
+	}
+	now = func() time.Time {
+		return time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)
+	}
+
+	return &calls, func() {
+		gitRun, gitBranch, now = oldGitRun, oldGitBranch, oldNow
+	}
+}
+
+func TestMobRemoveDev(t *testing.T) {
+	m := &mob{Devs: []string{"ak", "km", "an"}, Driver: 1}
+	m.removeDev("km")
+	assert.Equal(t, []string{"ak", "an"}, m.Devs)
+	assert.Equal(t, "an", m.driver())
+
+	m = &mob{Devs: []string{"ak", "km", "an"}, Driver: 2}
+	m.removeDev("ak")
+	assert.Equal(t, "an", m.driver())
+
+	m.removeDev("xx")
+	assert.Equal(t, []string{"km", "an"}, m.Devs)
+}
+
+func TestMobRotation(t *testing.T) {
+	start := time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)
+	m := &mob{Devs: []string{"ak", "km", "an"}, Interval: "10m", TurnStartedAt: start}
+
+	assert.Equal(t, "ak", m.driver())
+	assert.Equal(t, "km", m.nextDriver())
+	assert.Equal(t, "driver: ak (6m0s left, km is next)", m.status(start.Add(4*time.Minute)))
+	assert.Equal(t, "driver: ak (turn over 2m0s ago, km is next)", m.status(start.Add(12*time.Minute)))
+
+	m.rotate(start.Add(12 * time.Minute))
+	m.rotate(start.Add(20 * time.Minute))
+
+	assert.Equal(t, "an", m.driver())
+	assert.Equal(t, "ak", m.nextDriver())
+	assert.Equal(t, 10*time.Minute, m.remaining(start.Add(20*time.Minute)))
+
+	m.Interval = ""
+	assert.Equal(t, defaultMobInterval, m.interval())
+}
+
+func TestDataStartMob(t *testing.T) {
+	calls, restore := stubGit(map[string]string{"rev-parse": "abc123"}, "")
+	defer restore()
+
+	d := newMobTestData()
+
+	m, err := d.startMob("/a", []string{"ak", "@payments"}, 5*time.Minute)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &mob{
+		Devs:          []string{"ak", "km", "an"},
+		Interval:      "5m0s",
+		TurnStartedAt: now(),
+		Branch:        "mob/master",
+		BaseBranch:    "master",
+		BaseCommit:    "abc123",
+	}, m)
+	assert.Equal(t, m, d.Repos["/a"].Mob)
+	assert.Equal(t, []string{"rev-parse HEAD", "checkout -b mob/master"}, *calls)
+	assert.Equal(t, []string{"ak", "km", "an"}, d.Repos["/a"].sessionDevs())
+
+	_, err = d.startMob("/a", []string{"ak", "km"}, 5*time.Minute)
+	if assert.Error(t, err) {
+		assert.Equal(t, "mob already in progress on branch mob/master", err.Error())
+	}
+}
+
+func TestDataStartMobErrors(t *testing.T) {
+	tests := []struct {
+		wd      string
+		devIDs  []string
+		failing string
+		errMsg  string
+	}{
+		{
+			wd:     "/b",
+			devIDs: []string{"ak", "km"},
+			errMsg: "no repo with path /b found",
+		},
+		{
+			wd:     "/a",
+			devIDs: []string{"ak"},
+			errMsg: "a mob needs at least 2 devs",
+		},
+		{
+			wd:     "/a",
+			devIDs: []string{"ak", "shobhit"},
+			errMsg: "dev ids validation failed: no dev with id shobhit found",
+		},
+		{
+			wd:      "/a",
+			devIDs:  []string{"ak", "km"},
+			failing: "checkout",
+			errMsg:  "create branch mob/master failed: failed",
+		},
+	}
+
+	for _, tt := range tests {
+		_, restore := stubGit(nil, tt.failing)
+
+		d := newMobTestData()
+		_, err := d.startMob(tt.wd, tt.devIDs, time.Minute)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, tt.errMsg, err.Error())
+		}
+		assert.Nil(t, d.Repos["/a"].Mob)
+
+		restore()
+	}
+}
+
+func TestDataNextMob(t *testing.T) {
+	tests := []struct {
+		status string
+		calls  []string
+	}{
+		{
+			status: "",
+			calls:  []string{"status --porcelain"},
+		},
+		{
+			status: " M xp.go",
+			calls: []string{
+				"status --porcelain",
+				"add --all",
+				"commit --no-verify -m mob: wip by ak",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		calls, restore := stubGit(map[string]string{"status": tt.status}, "")
+
+		d := newMobTestData()
+		d.Repos["/a"].Mob = &mob{Devs: []string{"ak", "km"}}
+
+		m, err := d.nextMob("/a")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "km", m.driver())
+			assert.Equal(t, now(), m.TurnStartedAt)
+			assert.Equal(t, tt.calls, *calls)
+		}
+
+		restore()
+	}
+
+	d := newMobTestData()
+	_, err := d.nextMob("/a")
+	if assert.Error(t, err) {
+		assert.Equal(t, "no mob in progress", err.Error())
+	}
+}
+
+func TestDataDoneMob(t *testing.T) {
+	tests := []struct {
+		desc    string
+		log     string
+		failing string
+		calls   []string
+		mob     bool
+		errMsg  string
+	}{
+		{
+			desc: "wip commits",
+			log:  "mob: wip by ak\n\nCo-authored-by: Karan Misra <karan@beef.com>\nCo-authored-by: Shobhit <shobhit@beef.com>",
+			calls: []string{
+				"status --porcelain",
+				"log --format=%B abc123..HEAD",
+				"reset --soft abc123",
+				"commit -m Add mob feature\n\n" +
+					"Co-authored-by: akshat <akshat@beef.com>\n" +
+					"Co-authored-by: Karan Misra <karan@beef.com>\n" +
+					"Co-authored-by: Shobhit <shobhit@beef.com>\n",
+				"checkout master",
+				"merge --ff-only mob/master",
+				"branch -D mob/master",
+			},
+		},
+		{
+			desc: "no wip commits",
+			calls: []string{
+				"status --porcelain",
+				"log --format=%B abc123..HEAD",
+				"checkout master",
+				"merge --ff-only mob/master",
+				"branch -D mob/master",
+			},
+		},
+		{
+			desc:    "squashed commit rejected",
+			log:     "mob: wip by ak",
+			failing: "commit",
+			mob:     true,
+			errMsg:  "commit squashed wip failed: failed",
+		},
+		{
+			desc:    "base branch moved",
+			log:     "mob: wip by ak",
+			failing: "merge",
+			mob:     true,
+			errMsg:  "master could not be fast-forwarded, merge mob/master manually: failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Logf("case: %s", tt.desc)
+
+		calls, restore := stubGit(map[string]string{"log": tt.log}, tt.failing)
+
+		d := newMobTestData()
+		d.Repos["/a"].Mob = &mob{
+			Devs:       []string{"ak", "km"},
+			Branch:     "mob/master",
+			BaseBranch: "master",
+			BaseCommit: "abc123",
+		}
+
+		err := d.doneMob("/a", "Add mob feature\n")
+
+		assert.Equal(t, tt.mob, d.Repos["/a"].Mob != nil)
+		if tt.failing == "merge" {
+			assert.True(t, d.Repos["/a"].Mob.Squashed)
+		}
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+		} else if assert.NoError(t, err) {
+			assert.Equal(t, tt.calls, *calls)
+		}
+
+		restore()
+	}
+}
+
+func TestDataDoneMobRetry(t *testing.T) {
+	d := newMobTestData()
+	d.Repos["/a"].Mob = &mob{
+		Devs:       []string{"ak", "km"},
+		Branch:     "mob/master",
+		BaseBranch: "master",
+		BaseCommit: "abc123",
+	}
+
+	_, restore := stubGit(map[string]string{"log": "mob: wip by ak"}, "merge")
+	err := d.doneMob("/a", "Add mob feature\n")
+	restore()
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "master could not be fast-forwarded, merge mob/master manually: failed", err.Error())
+	}
+	if !assert.NotNil(t, d.Repos["/a"].Mob) {
+		return
+	}
+
+	// The retry merges the already squashed commit, without squashing
+	// again what is on the mob branch by then.
+	calls, restore := stubGit(map[string]string{"log": "mob: wip by ak"}, "")
+	defer restore()
+
+	if assert.NoError(t, d.doneMob("/a", "Add mob feature\n")) {
+		assert.Equal(t, []string{
+			"checkout master",
+			"merge --ff-only mob/master",
+			"branch -D mob/master",
+		}, *calls)
+	}
+	assert.Nil(t, d.Repos["/a"].Mob)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Orders in which co-authors can be written back.
+const (
+	orderEmail     = "email"
+	orderName      = "name"
+	orderFirstLine = "first-line"
+	orderRoster    = "roster"
+)
+
+var coAuthorOrders = []string{orderEmail, orderName, orderFirstLine, orderRoster}
+
+// emailKey is what emails are compared by: case insensitive, and with
+// any +tag dropped from the local part, so Ak+xp@X.com and ak@x.com are
+// the same person.
+func emailKey(email string) string {
+	email = strings.ToLower(email)
+
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return email
+	}
+	local, domain := email[:at], email[at:]
+	if i := strings.Index(local, "+"); i != -1 {
+		local = local[:i]
+	}
+	return local + domain
+}
+
+func sameEmail(a, b string) bool {
+	return emailKey(a) == emailKey(b)
+}
+
+// coAuthorSet holds the devs to credit, de-duplicated by emailKey and
+// remembering the order they were added in.
+type coAuthorSet struct {
+	devs map[string]*attributedDev
+	keys []string
+}
+
+func newCoAuthorSet() *coAuthorSet {
+	return &coAuthorSet{devs: make(map[string]*attributedDev)}
+}
+
+// add adds the dev unless one with the same email is already present,
+// in which case the first one wins.
+func (s *coAuthorSet) add(a *attributedDev) {
+	key := emailKey(a.dev.Email)
+	if _, ok := s.devs[key]; ok {
+		return
+	}
+	s.devs[key] = a
+	s.keys = append(s.keys, key)
+}
+
+func (s *coAuthorSet) has(email string) bool {
+	_, ok := s.devs[emailKey(email)]
+	return ok
+}
+
+func (s *coAuthorSet) len() int {
+	return len(s.keys)
+}
+
+// sorted returns the devs in the given order. For orderRoster, the devs
+// come in the order of roster (dev emails), followed by the rest in the
+// order they were added in.
+func (s *coAuthorSet) sorted(order string, roster []string) []*attributedDev {
+	keys := make([]string, len(s.keys))
+	copy(keys, s.keys)
+
+	switch order {
+	case orderFirstLine:
+		// Already in the order they were added in.
+
+	case orderName:
+		sort.SliceStable(keys, func(i, j int) bool {
+			ni, nj := strings.ToLower(s.devs[keys[i]].dev.Name), strings.ToLower(s.devs[keys[j]].dev.Name)
+			if ni != nj {
+				return ni < nj
+			}
+			return keys[i] < keys[j]
+		})
+
+	case orderRoster:
+		pos := make(map[string]int)
+		for i, email := range roster {
+			if _, ok := pos[emailKey(email)]; !ok {
+				pos[emailKey(email)] = i
+			}
+		}
+		rank := func(key string) int {
+			if p, ok := pos[key]; ok {
+				return p
+			}
+			return len(roster)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			return rank(keys[i]) < rank(keys[j])
+		})
+
+	default:
+		sort.Strings(keys)
+	}
+
+	devs := make([]*attributedDev, len(keys))
+	for i, key := range keys {
+		devs[i] = s.devs[key]
+	}
+	return devs
+}
+
+func parseCoAuthorOrder(order string) (string, error) {
+	for _, o := range coAuthorOrders {
+		if order == o {
+			return order, nil
+		}
+	}
+	return "", errors.Errorf("unknown order %s, expected one of %s", order, strings.Join(coAuthorOrders, ", "))
+}
+
+func (d *data) updateRepoCoAuthorOrder(wd, order string) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	order, err := parseCoAuthorOrder(order)
+	if err != nil {
+		return err
+	}
+
+	// The default is not written out to keep the config lean.
+	if order == orderEmail {
+		order = ""
+	}
+	repo.CoAuthorOrder = order
+
+	return nil
+}
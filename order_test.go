@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailKey(t *testing.T) {
+	tests := []struct {
+		email string
+		key   string
+	}{
+		{email: "ak@x.com", key: "ak@x.com"},
+		{email: "Ak@X.com", key: "ak@x.com"},
+		{email: "ak+xp@x.com", key: "ak@x.com"},
+		{email: "AK+xp+1@X.COM", key: "ak@x.com"},
+		{email: "ak", key: "ak"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.key, emailKey(tt.email), "email %s", tt.email)
+	}
+}
+
+func TestCoAuthorSetSorted(t *testing.T) {
+	s := newCoAuthorSet()
+	for _, dev := range []*dev{
+		{Name: "zed", Email: "a@x.com"},
+		{Name: "Yan", Email: "c@x.com"},
+		{Name: "xavi", Email: "b@x.com"},
+		{Name: "Yan Again", Email: "C+dup@x.com"},
+	} {
+		s.add(&attributedDev{dev: dev})
+	}
+
+	names := func(devs []*attributedDev) []string {
+		var names []string
+		for _, a := range devs {
+			names = append(names, a.dev.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, 3, s.len())
+	assert.True(t, s.has("A@X.com"))
+	assert.Equal(t, []string{"zed", "xavi", "Yan"}, names(s.sorted("", nil)))
+	assert.Equal(t, []string{"xavi", "Yan", "zed"}, names(s.sorted(orderName, nil)))
+	assert.Equal(t, []string{"zed", "Yan", "xavi"}, names(s.sorted(orderFirstLine, nil)))
+	assert.Equal(t, []string{"Yan", "zed", "xavi"}, names(s.sorted(orderRoster, []string{"c@x.com", "a@x.com"})))
+}
+
+func TestUpdateRepoCoAuthorOrder(t *testing.T) {
+	d := data{
+		Repos: map[string]*repo{
+			"/a": new(repo),
+		},
+	}
+
+	assert.NoError(t, d.updateRepoCoAuthorOrder("/a", orderName))
+	assert.Equal(t, orderName, d.Repos["/a"].CoAuthorOrder)
+
+	assert.NoError(t, d.updateRepoCoAuthorOrder("/a", orderEmail))
+	assert.Equal(t, "", d.Repos["/a"].CoAuthorOrder)
+
+	err := d.updateRepoCoAuthorOrder("/a", "age")
+	if assert.Error(t, err) {
+		assert.Equal(t, "unknown order age, expected one of email, name, first-line, roster", err.Error())
+	}
+
+	err = d.updateRepoCoAuthorOrder("/b", orderName)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no repo with path /b found", err.Error())
+	}
+}
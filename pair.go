@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// recentPairCommits is how far back the history is looked at for recent
+// pairs.
+const recentPairCommits = 200
+
+var errPickAborted = errors.New("pick aborted")
+
+// Keys understood by the picker. Arrow keys come in as escape sequences.
+const (
+	keyCtrlC     = 0x03
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+	keyCtrlH     = 0x08
+)
+
+// picker lets devs be picked from a list in the terminal, with search and
+// multi-select. It reads keys from in, so it can be driven by a scripted
+// input stream:
+//
+//	up, down (or ctrl-p, ctrl-n)  move the cursor
+//	space                         toggle the dev under the cursor
+//	any other text                search (backspace edits it)
+//	enter                         confirm
+//	esc, ctrl-c                   abort
+type picker struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	ids      []string
+	devs     map[string]*dev
+	recent   map[string]bool
+	query    string
+	cursor   int
+	selected map[string]bool
+
+	// drawn is the number of lines last rendered, so they can be redrawn
+	// in place.
+	drawn int
+}
+
+func newPicker(in io.Reader, out io.Writer, ids []string, devs map[string]*dev) *picker {
+	return &picker{
+		in:       bufio.NewReader(in),
+		out:      out,
+		ids:      ids,
+		devs:     devs,
+		recent:   make(map[string]bool),
+		selected: make(map[string]bool),
+	}
+}
+
+func (p *picker) matches(id string) bool {
+	if p.query == "" {
+		return true
+	}
+
+	dev := p.devs[id]
+	fields := append([]string{id, dev.Name, dev.Email, dev.Handle}, dev.Aliases...)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), strings.ToLower(p.query)) {
+			return true
+		}
+	}
+	return false
+}
+
+// visible are the ids listed for the current query.
+func (p *picker) visible() []string {
+	var ids []string
+	for _, id := range p.ids {
+		if p.matches(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (p *picker) render(ids []string) {
+	if p.drawn > 0 {
+		// Move back to the first line drawn and clear everything below.
+		fmt.Fprintf(p.out, "\x1b[%dA\x1b[J", p.drawn)
+	}
+
+	lines := []string{"search: " + p.query}
+	for i, id := range ids {
+		cursor, mark := " ", " "
+		if i == p.cursor {
+			cursor = ">"
+		}
+		if p.selected[id] {
+			mark = "x"
+		}
+		line := fmt.Sprintf("%s [%s] %-10s %s", cursor, mark, id, p.devs[id])
+		if p.recent[id] {
+			line += " (recent)"
+		}
+		lines = append(lines, line)
+	}
+	if len(ids) == 0 {
+		lines = append(lines, fmt.Sprintf("no devs matching %q", p.query))
+	}
+	lines = append(lines, "up/down move, space toggles, type to search, enter confirms, esc quits")
+
+	for _, line := range lines {
+		fmt.Fprintln(p.out, line)
+	}
+	p.drawn = len(lines)
+}
+
+// readKey reads the next key, mapping arrow keys to ctrl-p and ctrl-n. An
+// escape not followed by a sequence is returned as is.
+func (p *picker) readKey() (rune, error) {
+	r, _, err := p.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r != keyEscape || p.in.Buffered() < 2 {
+		return r, nil
+	}
+
+	if next, _ := p.in.Peek(1); next[0] != '[' {
+		return r, nil
+	}
+	seq := make([]byte, 2)
+	if _, err := io.ReadFull(p.in, seq); err != nil {
+		return 0, err
+	}
+	switch seq[1] {
+	case 'A':
+		return keyCtrlP, nil
+	case 'B':
+		return keyCtrlN, nil
+	}
+	// Other sequences (left, right, ...) are ignored.
+	return 0, nil
+}
+
+// pick runs the picker until the selection is confirmed, and returns the
+// selected ids in the order they are listed in.
+func (p *picker) pick() ([]string, error) {
+	restore, err := rawTerminal(p.in, p.out)
+	if err != nil {
+		return nil, errors.Wrap(err, "set up terminal failed")
+	}
+	defer restore()
+
+	for {
+		visible := p.visible()
+		if p.cursor >= len(visible) {
+			p.cursor = len(visible) - 1
+		}
+		if p.cursor < 0 {
+			p.cursor = 0
+		}
+		p.render(visible)
+
+		key, err := p.readKey()
+		if err == io.EOF {
+			return nil, errPickAborted
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "read input failed")
+		}
+
+		switch key {
+		case '\r', '\n':
+			var ids []string
+			for _, id := range p.ids {
+				if p.selected[id] {
+					ids = append(ids, id)
+				}
+			}
+			return ids, nil
+
+		case keyEscape, keyCtrlC:
+			return nil, errPickAborted
+
+		case keyCtrlP:
+			p.cursor--
+
+		case keyCtrlN:
+			p.cursor++
+
+		case ' ':
+			if p.cursor < len(visible) {
+				id := visible[p.cursor]
+				p.selected[id] = !p.selected[id]
+			}
+
+		case keyBackspace, keyCtrlH:
+			if p.query != "" {
+				q := []rune(p.query)
+				p.query = string(q[:len(q)-1])
+				p.cursor = 0
+			}
+
+		default:
+			if unicode.IsPrint(key) {
+				p.query += string(key)
+				p.cursor = 0
+			}
+		}
+	}
+}
+
+// prompt reads the next line, failing when the input is exhausted.
+func (p *picker) prompt() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", errPickAborted
+	}
+	if err != nil && err != io.EOF {
+		return "", errors.Wrap(err, "read input failed")
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// rawTerminal switches the terminal in reads from to read key by key,
+// without echo, returning a func restoring it. Nothing is done when the
+// input is not a terminal, as when the picker is scripted.
+var rawTerminal = func(in io.Reader, out io.Writer) (func(), error) {
+	nop := func() {}
+
+	f, ok := in.(*os.File)
+	if !ok {
+		return nop, nil
+	}
+	if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nop, nil
+	}
+
+	stty := func(args ...string) (string, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = f
+		output, err := cmd.Output()
+		return strings.TrimSpace(string(output)), err
+	}
+
+	state, err := stty("-g")
+	if err != nil {
+		return nil, errors.Wrap(err, "read terminal state failed")
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, errors.Wrap(err, "set terminal raw failed")
+	}
+
+	return func() {
+		if _, err := stty(state); err != nil {
+			log.Printf("could not restore terminal: %v", err)
+		}
+	}, nil
+}
+
+// recentPairs returns the ids of the devs credited in the recent history
+// of the current repo, most recent first.
+func (d *data) recentPairs() []string {
+	msgs, err := gitRun("log", "-n", strconv.Itoa(recentPairCommits), "--format=%B")
+	if err != nil {
+		log.Printf("could not read recent pairs: %v", err)
+		return nil
+	}
+
+	var ids []string
+	for _, dev := range existingDevs(msgs) {
+		if id, _ := d.lookupDevByEmail(dev.Email); id != "" && !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// pairCandidates are the devs which can be picked: the recent pairs
+// first, followed by the rest by id. Retired devs are left out.
+func (d *data) pairCandidates(recent []string) []string {
+	var ids, rest []string
+	for _, id := range recent {
+		if dev := d.Devs[id]; dev != nil && !dev.Retired {
+			ids = append(ids, id)
+		}
+	}
+	for id, dev := range d.Devs {
+		if !dev.Retired && !contains(ids, id) {
+			rest = append(rest, id)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(ids, rest...)
+}
+
+// pair lets the devs working on the repo in wd be picked interactively,
+// along with the issue id being worked on.
+func (d *data) pair(wd string, in io.Reader, out io.Writer) error {
+	repoPath, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	issues, err := newIssueMatcher(repo.Trackers)
+	if err != nil {
+		return errors.Wrapf(err, "invalid trackers for repo %s", repoPath)
+	}
+
+	recent := d.recentPairs()
+
+	p := newPicker(in, out, d.pairCandidates(recent), d.Devs)
+	for _, id := range recent {
+		p.recent[id] = true
+	}
+	for _, id := range d.expandDevIDs(repo.Devs) {
+		p.selected[id] = true
+	}
+
+	devIDs, err := p.pick()
+	if err != nil {
+		return err
+	}
+
+	for {
+		if repo.IssueID != "" {
+			fmt.Fprintf(out, "issue id (enter keeps %s, - clears): ", repo.IssueID)
+		} else {
+			fmt.Fprint(out, "issue id (optional): ")
+		}
+
+		issueID, err := p.prompt()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case issueID == "":
+			issueID = repo.IssueID
+		case issueID == "-":
+			issueID = ""
+		case !issues.match(issueID):
+			fmt.Fprintf(out, "%s is not a valid issue id (trackers: %s)\n", issueID, issues)
+			continue
+		}
+
+		if err := d.updateRepoDevs(wd, devIDs); err != nil {
+			return err
+		}
+		// The roles were set for the previous pair.
+		repo.Driver = ""
+		repo.Navigators = nil
+		repo.IssueID = strings.TrimPrefix(issueID, "#")
+
+		return nil
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	keyUp   = "\x1b[A"
+	keyDown = "\x1b[B"
+)
+
+func TestPicker(t *testing.T) {
+	devs := map[string]*dev{
+		"karan":  {Name: "Karan Misra", Email: "karan@beef.com"},
+		"anand":  {Name: "Anand Shankar", Email: "anand@beef.com"},
+		"akshat": {Name: "Akshat Shah", Email: "akshat@beef.com", Handle: "akshat-gh"},
+	}
+	ids := []string{"akshat", "anand", "karan"}
+
+	tests := []struct {
+		desc     string
+		input    string
+		selected []string
+		devIDs   []string
+		errMsg   string
+	}{
+		{
+			desc:   "multi-select",
+			input:  " " + keyDown + keyDown + " \r",
+			devIDs: []string{"akshat", "karan"},
+		},
+		{
+			desc:     "toggle off preselected",
+			input:    keyDown + " " + keyDown + " \n",
+			selected: []string{"anand"},
+			devIDs:   []string{"karan"},
+		},
+		{
+			desc:   "search",
+			input:  "MIS \x7f\x7f\x7f \n",
+			devIDs: []string{"akshat", "karan"},
+		},
+		{
+			desc:   "search by handle",
+			input:  "gh \n",
+			devIDs: []string{"akshat"},
+		},
+		{
+			desc:   "cursor stops at the ends",
+			input:  keyUp + keyUp + " " + strings.Repeat(keyDown, 5) + " \n",
+			devIDs: []string{"akshat", "karan"},
+		},
+		{
+			desc:   "nothing matching",
+			input:  "zzz \n",
+			devIDs: nil,
+		},
+		{
+			desc:   "escape quits",
+			input:  " \x1b",
+			errMsg: "pick aborted",
+		},
+		{
+			desc:   "ctrl-c quits",
+			input:  " \x03",
+			errMsg: "pick aborted",
+		},
+		{
+			desc:   "input closed",
+			input:  " ",
+			errMsg: "pick aborted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Logf("case: %s", tt.desc)
+
+		var out bytes.Buffer
+		p := newPicker(strings.NewReader(tt.input), &out, ids, devs)
+		for _, id := range tt.selected {
+			p.selected[id] = true
+		}
+
+		devIDs, err := p.pick()
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.devIDs, devIDs)
+		}
+	}
+}
+
+func TestPickerRender(t *testing.T) {
+	var out bytes.Buffer
+	p := newPicker(strings.NewReader("z\x1b"), &out, []string{"karan", "anand"}, map[string]*dev{
+		"karan": {Name: "Karan Misra", Email: "karan@beef.com"},
+		"anand": {Name: "Anand Shankar", Email: "anand@beef.com"},
+	})
+	p.recent["karan"] = true
+	p.selected["anand"] = true
+
+	_, err := p.pick()
+	assert.Equal(t, errPickAborted, err)
+
+	assert.Equal(t, ""+
+		"search: \n"+
+		"> [ ] karan      Karan Misra <karan@beef.com> (recent)\n"+
+		"  [x] anand      Anand Shankar <anand@beef.com>\n"+
+		"up/down move, space toggles, type to search, enter confirms, esc quits\n"+
+		"\x1b[4A\x1b[J"+
+		"search: z\n"+
+		"no devs matching \"z\"\n"+
+		"up/down move, space toggles, type to search, enter confirms, esc quits\n", out.String())
+}
+
+func TestPair(t *testing.T) {
+	newData := func() *data {
+		return &data{
+			Devs: map[string]*dev{
+				"karan":  {Name: "Karan Misra", Email: "karan@beef.com"},
+				"anand":  {Name: "Anand Shankar", Email: "anand@beef.com"},
+				"akshat": {Name: "Akshat Shah", Email: "akshat@beef.com"},
+				"rohan":  {Name: "Rohan Das", Email: "rohan@beef.com", Retired: true},
+			},
+			Repos: map[string]*repo{
+				"/a": {Devs: []string{"anand"}, IssueID: "GOJ-1", Driver: "anand", Navigators: []string{"akshat"}},
+			},
+		}
+	}
+
+	log := "Line 2\n\nCo-authored-by: Akshat Shah <AKSHAT@beef.com>\n\n" +
+		"Line 1\n\nCo-authored-by: Rohan Das <rohan@beef.com>\nCo-authored-by: Anand Shankar <anand@beef.com>\n"
+
+	tests := []struct {
+		desc    string
+		wd      string
+		input   string
+		devs    []string
+		issueID string
+		errMsg  string
+	}{
+		{
+			desc:    "recent pairs listed first",
+			input:   " \n\n",
+			devs:    []string{"akshat", "anand"},
+			issueID: "GOJ-1",
+		},
+		{
+			desc:    "new issue id",
+			input:   keyDown + " \nfoo\nGOJ-2\n",
+			issueID: "GOJ-2",
+		},
+		{
+			desc:    "github issue id",
+			input:   "\n#12\n",
+			devs:    []string{"anand"},
+			issueID: "12",
+		},
+		{
+			desc:  "issue id cleared",
+			input: "\n-\n",
+			devs:  []string{"anand"},
+		},
+		{
+			desc:   "aborted",
+			input:  " \x1b",
+			devs:   []string{"anand"},
+			errMsg: "pick aborted",
+		},
+		{
+			desc:   "unknown repo",
+			wd:     "/b",
+			errMsg: "no repo with path /b found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Logf("case: %s", tt.desc)
+
+		_, restore := stubGit(map[string]string{"log": log}, "")
+
+		d := newData()
+		wd := tt.wd
+		if wd == "" {
+			wd = "/a"
+		}
+
+		var out bytes.Buffer
+		err := d.pair(wd, strings.NewReader(tt.input), &out)
+		restore()
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			if tt.devs != nil {
+				assert.Equal(t, tt.devs, d.Repos["/a"].Devs)
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.devs, d.Repos["/a"].Devs)
+			assert.Equal(t, tt.issueID, d.Repos["/a"].IssueID)
+			assert.Empty(t, d.Repos["/a"].Driver)
+			assert.Empty(t, d.Repos["/a"].Navigators)
+		}
+	}
+}
+
+func TestPairCandidates(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan":  {Name: "Karan Misra", Email: "karan@beef.com"},
+			"anand":  {Name: "Anand Shankar", Email: "anand@beef.com"},
+			"akshat": {Name: "Akshat Shah", Email: "akshat@beef.com"},
+			"rohan":  {Name: "Rohan Das", Email: "rohan@beef.com", Retired: true},
+		},
+	}
+
+	assert.Equal(t, []string{"karan", "akshat", "anand"}, d.pairCandidates([]string{"karan", "rohan"}))
+}
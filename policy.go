@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// policy is the set of rules the commit-msg hook enforces for a repo.
+type policy struct {
+	// RequireIssueID requires an issue id on all branches except
+	// MainBranches.
+	RequireIssueID bool     `json:"requireIssueId,omitempty"`
+	MainBranches   []string `json:"mainBranches,omitempty"`
+
+	// RequireCoAuthor requires at least one co-author while a pairing
+	// session is active, i.e. the repo has devs other than the author.
+	RequireCoAuthor bool `json:"requireCoAuthor,omitempty"`
+
+	MinSubjectLength int `json:"minSubjectLength,omitempty"`
+	MaxSubjectLength int `json:"maxSubjectLength,omitempty"`
+
+	// RejectPrefix rejects subjects still starting with a [..] block,
+	// which happens when xp could not resolve it.
+	RejectPrefix bool `json:"rejectPrefix,omitempty"`
+
+	// ConventionalTypes requires a Conventional Commits header with one
+	// of the given types.
+	ConventionalTypes []string `json:"conventionalTypes,omitempty"`
+}
+
+var defaultMainBranches = []string{"main", "master"}
+
+// noVerifyEnv can be set to skip the policy checks for a single commit.
+const noVerifyEnv = "XP_NO_VERIFY"
+
+func (p *policy) isMainBranch(branch string) bool {
+	mainBranches := p.MainBranches
+	if len(mainBranches) == 0 {
+		mainBranches = defaultMainBranches
+	}
+
+	for _, b := range mainBranches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
+
+var prefixRegexp = regexp.MustCompile(`^\[[^\]]*\]`)
+
+// check returns a readable description of every rule the message breaks.
+func (p *policy) check(msg, branch string, issues *issueMatcher, sessionDevs int) []string {
+	var violations []string
+
+	subject := msgSubject(msg)
+	subjectLen := utf8.RuneCountInString(subject)
+
+	if p.RequireIssueID && branch != "" && !p.isMainBranch(branch) {
+		if existingIssueID(msg, issues) == "" {
+			violations = append(violations, fmt.Sprintf("an issue id (trackers: %s) is required on branch %s", issues, branch))
+		}
+	}
+
+	if p.RequireCoAuthor && sessionDevs > 0 && len(existingDevs(msg)) == 0 {
+		violations = append(violations, "at least one co-author is required while pairing")
+	}
+
+	if p.MinSubjectLength > 0 && subjectLen < p.MinSubjectLength {
+		violations = append(violations, fmt.Sprintf("subject is %d characters long, minimum is %d", subjectLen, p.MinSubjectLength))
+	}
+
+	if p.MaxSubjectLength > 0 && subjectLen > p.MaxSubjectLength {
+		violations = append(violations, fmt.Sprintf("subject is %d characters long, maximum is %d", subjectLen, p.MaxSubjectLength))
+	}
+
+	if p.RejectPrefix {
+		_, rest := splitConventionalHeader(subject)
+		if prefixRegexp.MatchString(rest) {
+			violations = append(violations, fmt.Sprintf("subject starts with unresolved %s", prefixRegexp.FindString(rest)))
+		}
+	}
+
+	if len(p.ConventionalTypes) != 0 {
+		if v := checkConventionalType(subject, p.ConventionalTypes); v != "" {
+			violations = append(violations, v)
+		}
+	}
+
+	return violations
+}
+
+// msgSubject returns the first line of the message which is neither
+// empty nor a git comment.
+func msgSubject(msg string) string {
+	scanner := bufio.NewScanner(strings.NewReader(msg))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+// updateRepoPolicy changes the policy of the repo with update, keeping the
+// rules it does not change.
+func (d *data) updateRepoPolicy(wd string, update func(p *policy)) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	p := new(policy)
+	if repo.Policy != nil {
+		*p = *repo.Policy
+	}
+	update(p)
+
+	if p.MinSubjectLength > 0 && p.MaxSubjectLength > 0 && p.MinSubjectLength > p.MaxSubjectLength {
+		return errors.Errorf("min subject length %d is more than max subject length %d", p.MinSubjectLength, p.MaxSubjectLength)
+	}
+
+	repo.Policy = p
+
+	return nil
+}
+
+func (d *data) checkMsg(wd, msgFile string) error {
+	repoPath, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	if repo.Policy == nil {
+		return nil
+	}
+
+	author, err := gitVar("GIT_AUTHOR_IDENT")
+	if err != nil {
+		return errors.Wrap(err, "get author info failed")
+	}
+	_, authorEmail := nameEmail(author)
+
+	issues, err := newIssueMatcher(repo.Trackers)
+	if err != nil {
+		return errors.Wrapf(err, "invalid trackers for repo %s", repoPath)
+	}
+
+	msg, err := ioutil.ReadFile(msgFile)
+	if err != nil {
+		return errors.Wrapf(err, "read commit msg from file %s failed", msgFile)
+	}
+
+	branch, err := gitBranch()
+	if err != nil {
+		// Detached HEAD (during a rebase for ex) has no branch, so the
+		// branch specific rules do not apply.
+		log.Printf("could not determine branch: %v", err)
+	}
+
+	violations := repo.Policy.check(string(msg), branch, issues, d.pairingDevCount(repo, authorEmail))
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return errors.Errorf("commit message rejected by the policy of repo %s:\n  - %s\n(set %s=1 to bypass)",
+		repoPath, strings.Join(violations, "\n  - "), noVerifyEnv)
+}
+
+// pairingDevCount returns the number of devs pairing (or mobbing) with
+// the author.
+func (d *data) pairingDevCount(repo *repo, authorEmail string) int {
+	var n int
+	for _, devID := range d.expandDevIDs(repo.sessionDevs()) {
+		if dev := d.lookupDev(devID); dev != nil && !dev.hasEmail(authorEmail) {
+			n++
+		}
+	}
+	return n
+}
+
+var gitBranch = func() (string, error) {
+	output, err := exec.Command("git", "symbolic-ref", "--short", "HEAD").Output()
+	if err != nil {
+		return "", errors.Wrap(err, "git exec failed")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
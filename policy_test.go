@@ -0,0 +1,292 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		desc        string
+		policy      policy
+		msg         string
+		branch      string
+		sessionDevs int
+		violations  []string
+	}{
+		{
+			desc:   "empty policy",
+			msg:    "[ak] Line 1",
+			branch: "feature",
+		},
+		{
+			desc:   "issue id missing on feature branch",
+			policy: policy{RequireIssueID: true},
+			msg:    "Line 1\n\nCo-authored-by: akshat <akshat@beef.com>\n",
+			branch: "feature",
+			violations: []string{
+				"an issue id (trackers: jira, github) is required on branch feature",
+			},
+		},
+		{
+			desc:   "issue id present on feature branch",
+			policy: policy{RequireIssueID: true},
+			msg:    "Line 1\n\nIssue-id: GOJ-1\n\n",
+			branch: "feature",
+		},
+		{
+			desc:   "issue id missing on main branch",
+			policy: policy{RequireIssueID: true},
+			msg:    "Line 1",
+			branch: "master",
+		},
+		{
+			desc:   "issue id missing on configured main branch",
+			policy: policy{RequireIssueID: true, MainBranches: []string{"trunk"}},
+			msg:    "Line 1",
+			branch: "master",
+			violations: []string{
+				"an issue id (trackers: jira, github) is required on branch master",
+			},
+		},
+		{
+			desc:   "issue id missing on detached head",
+			policy: policy{RequireIssueID: true},
+			msg:    "Line 1",
+		},
+		{
+			desc:        "co-author missing while pairing",
+			policy:      policy{RequireCoAuthor: true},
+			msg:         "Line 1\n\n",
+			sessionDevs: 1,
+			violations: []string{
+				"at least one co-author is required while pairing",
+			},
+		},
+		{
+			desc:   "co-author missing while not pairing",
+			policy: policy{RequireCoAuthor: true},
+			msg:    "Line 1\n\n",
+		},
+		{
+			desc:   "subject length limits",
+			policy: policy{MinSubjectLength: 10, MaxSubjectLength: 20},
+			msg:    "# comment\n\nLine 1\n\nLine 2 is long enough",
+			violations: []string{
+				"subject is 6 characters long, minimum is 10",
+			},
+		},
+		{
+			desc:   "unresolved prefix and long subject",
+			policy: policy{MaxSubjectLength: 10, RejectPrefix: true},
+			msg:    "[ak km] Line 1",
+			violations: []string{
+				"subject is 14 characters long, maximum is 10",
+				"subject starts with unresolved [ak km]",
+			},
+		},
+		{
+			desc:   "unresolved prefix after conventional commit header",
+			policy: policy{RejectPrefix: true},
+			msg:    "feat(api): [ak km] Line 1",
+			violations: []string{
+				"subject starts with unresolved [ak km]",
+			},
+		},
+		{
+			desc:   "conventional commit type not allowed",
+			policy: policy{ConventionalTypes: []string{"feat", "fix"}},
+			msg:    "chore: Line 1",
+			violations: []string{
+				"type chore is not one of feat, fix",
+			},
+		},
+		{
+			desc:   "conventional commit type allowed",
+			policy: policy{ConventionalTypes: []string{"feat", "fix"}},
+			msg:    "fix(api)!: Line 1",
+		},
+		{
+			desc:   "not a conventional commit",
+			policy: policy{ConventionalTypes: []string{"feat", "fix"}},
+			msg:    "Line 1",
+			violations: []string{
+				"subject is not a conventional commit header (types: feat, fix)",
+			},
+		},
+	}
+
+	issues, err := newIssueMatcher(nil)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Logf("case: %s", tt.desc)
+
+		violations := tt.policy.check(tt.msg, tt.branch, issues, tt.sessionDevs)
+		assert.Equal(t, tt.violations, violations)
+	}
+}
+
+func TestUpdateRepoPolicy(t *testing.T) {
+	d := data{
+		Repos: map[string]*repo{
+			"/a": new(repo),
+		},
+	}
+
+	assert.NoError(t, d.updateRepoPolicy("/a", func(p *policy) {
+		p.RequireIssueID = true
+	}))
+	assert.Equal(t, &policy{RequireIssueID: true}, d.Repos["/a"].Policy)
+
+	// Rules not changed are kept.
+	assert.NoError(t, d.updateRepoPolicy("/a", func(p *policy) {
+		p.MaxSubjectLength = 72
+	}))
+	assert.Equal(t, &policy{RequireIssueID: true, MaxSubjectLength: 72}, d.Repos["/a"].Policy)
+
+	err := d.updateRepoPolicy("/b", func(p *policy) {})
+	if assert.Error(t, err) {
+		assert.Equal(t, "no repo with path /b found", err.Error())
+	}
+
+	err = d.updateRepoPolicy("/a", func(p *policy) {
+		p.MinSubjectLength = 80
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "min subject length 80 is more than max subject length 72", err.Error())
+	}
+	assert.Equal(t, &policy{RequireIssueID: true, MaxSubjectLength: 72}, d.Repos["/a"].Policy)
+}
+
+func TestCheckMsg(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan": &dev{
+				Name: "Karan Misra", Email: "karan@beef.com",
+			},
+			"anand": &dev{
+				Name: "Anand Shankar", Email: "anand@beef.com",
+			},
+		},
+		Repos: map[string]*repo{
+			"/a": &repo{
+				Devs: []string{"karan"},
+				Policy: &policy{
+					RequireIssueID:  true,
+					RequireCoAuthor: true,
+				},
+			},
+			"/b": &repo{
+				Devs: []string{"karan"},
+			},
+			"/p": &repo{
+				Policy: &policy{RejectPrefix: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc   string
+		wd     string
+		author string
+		branch string
+		msg    string
+		errMsg string
+	}{
+		{
+			desc:   "no policy",
+			wd:     "/b",
+			author: "Anand Shankar <anand@beef.com>",
+			branch: "feature",
+			msg:    "Line 1",
+		},
+		{
+			desc:   "valid message",
+			wd:     "/a",
+			author: "Anand Shankar <anand@beef.com>",
+			branch: "feature",
+			msg:    "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:   "author is the only repo dev",
+			wd:     "/a",
+			author: "Karan Misra <karan@beef.com>",
+			branch: "feature",
+			msg:    "Line 1\n\nIssue-id: GOJ-1\n\n",
+		},
+		{
+			desc:   "invalid message",
+			wd:     "/a",
+			author: "Anand Shankar <anand@beef.com>",
+			branch: "feature",
+			msg:    "Line 1\n\n",
+			errMsg: "commit message rejected by the policy of repo /a:\n" +
+				"  - an issue id (trackers: jira, github) is required on branch feature\n" +
+				"  - at least one co-author is required while pairing\n" +
+				"(set XP_NO_VERIFY=1 to bypass)",
+		},
+		{
+			desc:   "unresolved prefix left in the message",
+			wd:     "/p",
+			author: "Anand Shankar <anand@beef.com>",
+			branch: "feature",
+			msg:    "[an|zz] Line 1\n",
+			errMsg: "commit message rejected by the policy of repo /p:\n" +
+				"  - subject starts with unresolved [an|zz]\n" +
+				"(set XP_NO_VERIFY=1 to bypass)",
+		},
+		{
+			desc:   "unknown repo",
+			wd:     "/c",
+			errMsg: "no repo with path /c found",
+		},
+	}
+
+	oldGitVar, oldGitBranch := gitVar, gitBranch
+	defer func() {
+		gitVar, gitBranch = oldGitVar, oldGitBranch
+	}()
+
+	for _, tt := range tests {
+		t.Logf("case: %s", tt.desc)
+
+		f, err := ioutil.TempFile("", "")
+		require.NoError(t, err)
+		defer os.Remove(f.Name())
+		defer f.Close()
+
+		require.NoError(t, ioutil.WriteFile(f.Name(), []byte(tt.msg), 0700))
+
+		gitVar = func(_ string) (string, error) {
+			return tt.author, nil
+		}
+		gitBranch = func() (string, error) {
+			if tt.branch == "" {
+				return "", errors.New("detached")
+			}
+			return tt.branch, nil
+		}
+
+		err = d.checkMsg(tt.wd, f.Name())
+
+		// The message is only validated, never rewritten.
+		msg, rerr := ioutil.ReadFile(f.Name())
+		require.NoError(t, rerr)
+		assert.Equal(t, tt.msg, string(msg))
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			continue
+		}
+
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+// preview writes the commit message the hooks would produce for msg in
+// wd, followed by where each piece of xp info came from.
+func (d *data) preview(w io.Writer, wd, msg string) error {
+	repoPath, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	author, err := gitVar("GIT_AUTHOR_IDENT")
+	if err != nil {
+		return errors.Wrap(err, "get author info failed")
+	}
+	authorName, authorEmail := nameEmail(author)
+
+	committer, err := gitVar("GIT_COMMITTER_IDENT")
+	if err != nil {
+		return errors.Wrap(err, "get committer info failed")
+	}
+	committerName, committerEmail := nameEmail(committer)
+
+	info, err := d.resolveInfo(repoPath, repo, author, committer, msg)
+	if err != nil {
+		return err
+	}
+
+	branch, err := gitBranch()
+	if err != nil {
+		log.Printf("could not determine branch: %v", err)
+	}
+
+	fmt.Fprint(w, info)
+	fmt.Fprintln(w, "---")
+	fmt.Fprintf(w, "repo: %s\n", repoPath)
+	fmt.Fprintf(w, "author: %s <%s>\n", authorName, authorEmail)
+	if committerEmail != authorEmail {
+		fmt.Fprintf(w, "committer: %s <%s>\n", committerName, committerEmail)
+	}
+	if branch != "" {
+		fmt.Fprintf(w, "branch: %s\n", branch)
+	}
+
+	if info.issueID != "" {
+		fmt.Fprintf(w, "issue id: %s (%s)\n", info.issueID, info.issueIDSource)
+	}
+	for _, a := range info.coAuthors {
+		fmt.Fprintf(w, "co-author: %s (%s)\n", a.dev, a.source)
+	}
+	for _, a := range info.skipped {
+		fmt.Fprintf(w, "skipped: %s (%s, same as author)\n", a.dev, a.source)
+	}
+	if !info.roles.empty() {
+		if info.roles.driver != nil {
+			fmt.Fprintf(w, "driver: %s (%s)\n", info.roles.driver, info.roles.source)
+		}
+		for _, dev := range info.roles.navigators {
+			fmt.Fprintf(w, "navigator: %s (%s)\n", dev, info.roles.source)
+		}
+	}
+	for _, dev := range info.signOffs {
+		fmt.Fprintf(w, "sign-off: %s\n", dev)
+	}
+
+	if repo.Policy == nil {
+		return nil
+	}
+
+	issues, err := newIssueMatcher(repo.Trackers)
+	if err != nil {
+		return errors.Wrapf(err, "invalid trackers for repo %s", repoPath)
+	}
+
+	violations := repo.Policy.check(info.String(), branch, issues, d.pairingDevCount(repo, authorEmail))
+	if len(violations) == 0 {
+		fmt.Fprintln(w, "policy: ok")
+	}
+	for _, v := range violations {
+		fmt.Fprintf(w, "policy violation: %s\n", v)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreview(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan": &dev{
+				Name: "Karan Misra", Email: "karan@beef.com",
+			},
+			"anand": &dev{
+				Name: "Anand Shankar", Email: "anand@beef.com",
+			},
+			"akshat": &dev{
+				Name: "Akshat Shah", Email: "akshat@beef.com",
+			},
+		},
+		Repos: map[string]*repo{
+			"/a": &repo{
+				Devs: []string{"karan", "anand"},
+			},
+			"/b": &repo{
+				Devs:   []string{"anand"},
+				Policy: &policy{RequireIssueID: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc   string
+		wd     string
+		msg    string
+		output string
+		errMsg string
+	}{
+		{
+			desc: "repo default",
+			wd:   "/a",
+			msg:  "Line 1",
+			output: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n" +
+				"---\n" +
+				"repo: /a\n" +
+				"author: Karan Misra <karan@beef.com>\n" +
+				"branch: feature\n" +
+				"co-author: Anand Shankar <anand@beef.com> (repo default)\n" +
+				"skipped: Karan Misra <karan@beef.com> (repo default, same as author)\n",
+		},
+		{
+			desc: "first line and existing trailer",
+			wd:   "/a",
+			msg:  "[GOJ-1,akshat] Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>",
+			output: "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\n" +
+				"---\n" +
+				"repo: /a\n" +
+				"author: Karan Misra <karan@beef.com>\n" +
+				"branch: feature\n" +
+				"issue id: GOJ-1 (first line)\n" +
+				"co-author: Akshat Shah <akshat@beef.com> (first line)\n",
+		},
+		{
+			desc: "existing trailers",
+			wd:   "/a",
+			msg:  "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Anand Shankar <anand@beef.com>",
+			output: "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n" +
+				"---\n" +
+				"repo: /a\n" +
+				"author: Karan Misra <karan@beef.com>\n" +
+				"branch: feature\n" +
+				"issue id: GOJ-1 (existing trailer)\n" +
+				"co-author: Anand Shankar <anand@beef.com> (existing trailer)\n",
+		},
+		{
+			desc: "policy violation",
+			wd:   "/b",
+			msg:  "Line 1",
+			output: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n" +
+				"---\n" +
+				"repo: /b\n" +
+				"author: Karan Misra <karan@beef.com>\n" +
+				"branch: feature\n" +
+				"co-author: Anand Shankar <anand@beef.com> (repo default)\n" +
+				"policy violation: an issue id (trackers: jira, github) is required on branch feature\n",
+		},
+		{
+			desc:   "unknown dev",
+			wd:     "/a",
+			msg:    "[GOJ-1,shobhit] Line 1",
+			errMsg: "non-existing dev shobhit provided in the first line",
+		},
+		{
+			desc:   "unknown repo",
+			wd:     "/c",
+			errMsg: "no repo with path /c found",
+		},
+	}
+
+	oldGitVar, oldGitBranch := gitVar, gitBranch
+	defer func() {
+		gitVar, gitBranch = oldGitVar, oldGitBranch
+	}()
+
+	gitVar = func(_ string) (string, error) {
+		return "Karan Misra <karan@beef.com> 1551654611 +0530", nil
+	}
+	gitBranch = func() (string, error) {
+		return "feature", nil
+	}
+
+	for _, tt := range tests {
+		t.Logf("case: %s", tt.desc)
+
+		var buf bytes.Buffer
+		err := d.preview(&buf, tt.wd, tt.msg)
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.output, buf.String())
+		}
+	}
+}
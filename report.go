@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// reportFile is the name of the HTML report written to the output
+// directory.
+const reportFile = "index.html"
+
+// reportView is what the HTML report is rendered from.
+type reportView struct {
+	Generated   string
+	Commits     int
+	Devs        []string
+	Matrix      []*matrixRow
+	NeverPaired []string
+	Trend       *trendView
+	Issues      []*issueView
+}
+
+type matrixRow struct {
+	Dev   string
+	Cells []*matrixCell
+}
+
+type matrixCell struct {
+	Count int
+	Self  bool
+}
+
+type trendView struct {
+	By      string
+	Periods []string
+	Rows    []*trendViewRow
+}
+
+type trendViewRow struct {
+	Name  string
+	Share string
+	Cells []*trendCell
+}
+
+// trendCell is the share of paired commits in a period. Level goes from 0
+// (none paired) to 7 (all paired), and is -1 without commits.
+type trendCell struct {
+	Level int
+	Title string
+}
+
+type issueView struct {
+	Title        string
+	Commits      int
+	Contributors string
+}
+
+func (d *data) matrixView(s *pairStats) ([]string, []*matrixRow, []string) {
+	ids := d.statsDevs(s)
+
+	var (
+		rows  []*matrixRow
+		never []string
+	)
+	for i, a := range ids {
+		row := &matrixRow{Dev: a}
+		for j, b := range ids {
+			cell := &matrixCell{Self: i == j}
+			if i != j {
+				cell.Count = s.pair(a, b)
+				if i < j && neverPaired(s, a, b) {
+					never = append(never, a+" & "+b)
+				}
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		rows = append(rows, row)
+	}
+	return ids, rows, never
+}
+
+func newTrendView(t *trend) *trendView {
+	v := &trendView{By: t.by}
+	for _, p := range t.periods {
+		v.Periods = append(v.Periods, periodLabel(p, t.by))
+	}
+
+	for _, r := range t.rows {
+		commits, paired := r.total()
+		row := &trendViewRow{
+			Name:  r.name,
+			Share: fmt.Sprintf("%d%% (%d/%d)", share(paired, commits), paired, commits),
+		}
+		for _, p := range t.periods {
+			cell := &trendCell{Level: -1, Title: periodLabel(p, t.by) + ": no commits"}
+			if n := r.commits[p]; n != 0 {
+				cell.Level = sparkLevel(r.paired[p], n)
+				cell.Title = fmt.Sprintf("%s: %d/%d paired", periodLabel(p, t.by), r.paired[p], n)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		v.Rows = append(v.Rows, row)
+	}
+	return v
+}
+
+// issueViews are the issues worked on, most commits first, each with how
+// many of their commits every contributor took part in.
+func issueViews(issues []*changelogIssue) []*issueView {
+	var views []*issueView
+	for _, issue := range issues {
+		counts := make(map[string]int)
+		for _, c := range issue.Commits {
+			for _, name := range c.Contributors {
+				counts[name]++
+			}
+		}
+
+		names := append([]string(nil), issue.Contributors...)
+		sort.SliceStable(names, func(i, j int) bool {
+			return counts[names[i]] > counts[names[j]]
+		})
+		contributors := make([]string, len(names))
+		for i, name := range names {
+			contributors[i] = fmt.Sprintf("%s (%d)", name, counts[name])
+		}
+
+		views = append(views, &issueView{
+			Title:        issue.title(),
+			Commits:      len(issue.Commits),
+			Contributors: strings.Join(contributors, ", "),
+		})
+	}
+
+	// The unlinked commits stay last.
+	sort.SliceStable(views, func(i, j int) bool {
+		if views[i].Title == unlinkedTitle || views[j].Title == unlinkedTitle {
+			return views[j].Title == unlinkedTitle && views[i].Title != unlinkedTitle
+		}
+		return views[i].Commits > views[j].Commits
+	})
+	return views
+}
+
+func (d *data) report(commits []*commit, by string) *reportView {
+	v := &reportView{
+		Generated: now().Format("2006-01-02 15:04"),
+		Commits:   len(commits),
+		Trend:     newTrendView(d.trend(commits, by)),
+		Issues:    issueViews(d.changelog(commits)),
+	}
+	v.Devs, v.Matrix, v.NeverPaired = d.matrixView(d.matrixStats(commits))
+	return v
+}
+
+// reportTemplate has everything inline, so the report works offline.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Pairing report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #e1e4e8; }
+table { border-collapse: collapse; }
+th, td { padding: 4px 8px; border: 1px solid #e1e4e8; }
+th { background: #f6f8fa; }
+td.n { text-align: right; }
+td.self { background: #f6f8fa; }
+td.never { color: #d73a49; text-align: center; }
+td.cell { width: 1.2em; padding: 0; }
+.l-1 { background: #fff; }
+.l0 { background: #ebedf0; }
+.l1 { background: #d4efd9; }
+.l2 { background: #bde6c5; }
+.l3 { background: #9be9a8; }
+.l4 { background: #6fd483; }
+.l5 { background: #40c463; }
+.l6 { background: #30a14e; }
+.l7 { background: #216e39; }
+.meta { color: #6a737d; }
+</style>
+</head>
+<body>
+<h1>Pairing report</h1>
+<p class="meta">{{.Commits}} commits, generated {{.Generated}}</p>
+
+<h2>Pair matrix</h2>
+{{if .Devs}}<table>
+<tr><th></th>{{range .Devs}}<th>{{.}}</th>{{end}}</tr>
+{{range .Matrix}}<tr><th>{{.Dev}}</th>{{range .Cells}}{{if .Self}}<td class="self"></td>{{else if .Count}}<td class="n">{{.Count}}</td>{{else}}<td class="never">&middot;</td>{{end}}{{end}}</tr>
+{{end}}</table>
+{{if .NeverPaired}}<p>Never paired: {{range $i, $p := .NeverPaired}}{{if $i}}, {{end}}{{$p}}{{end}}</p>{{end}}
+{{else}}<p>No commits.</p>
+{{end}}
+<h2>Paired commits by {{.Trend.By}}</h2>
+{{if .Trend.Rows}}<table>
+<tr><th></th>{{range .Trend.Periods}}<th title="{{.}}"></th>{{end}}<th>Paired</th></tr>
+{{range .Trend.Rows}}<tr><th>{{.Name}}</th>{{range .Cells}}<td class="cell l{{.Level}}" title="{{.Title}}"></td>{{end}}<td class="n">{{.Share}}</td></tr>
+{{end}}</table>
+<p class="meta">From {{index .Trend.Periods 0}}, darker is more paired.</p>
+{{else}}<p>No commits by devs in the roster.</p>
+{{end}}
+<h2>Issues</h2>
+{{if .Issues}}<table>
+<tr><th>Issue</th><th>Commits</th><th>Contributors</th></tr>
+{{range .Issues}}<tr><td>{{.Title}}</td><td class="n">{{.Commits}}</td><td>{{.Contributors}}</td></tr>
+{{end}}</table>
+{{else}}<p>No commits.</p>
+{{end}}
+</body>
+</html>
+`))
+
+func writeReport(w io.Writer, v *reportView) error {
+	return errors.Wrap(reportTemplate.Execute(w, v), "render report failed")
+}
+
+// repoReport writes the HTML report of the current repo, or of every repo
+// with allRepos, to dir and returns the path of the report.
+func (d *data) repoReport(dir, since string, allRepos bool, workers int, by string) (string, error) {
+	if dir == "" {
+		return "", errors.New("no output directory")
+	}
+	if err := checkPeriod(by); err != nil {
+		return "", err
+	}
+
+	commits, err := d.statsHistory(since, allRepos, workers)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrap(err, "create output directory failed")
+	}
+
+	path := filepath.Join(dir, reportFile)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrap(err, "create report failed")
+	}
+	defer f.Close()
+
+	if err := writeReport(f, d.report(commits, by)); err != nil {
+		return "", err
+	}
+	return path, errors.Wrap(f.Close(), "close report failed")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrixView(t *testing.T) {
+	d := data{Devs: map[string]*dev{
+		"karan":  {Name: "Karan Misra", Email: "karan@beef.com"},
+		"anand":  {Name: "Anand Shankar", Email: "anand@beef.com"},
+		"akshat": {Name: "Akshat Shah", Email: "akshat@beef.com"},
+	}}
+
+	s := newPairStats()
+	s.add([]string{"anand", "karan"})
+	s.add([]string{"anand", "karan"})
+	s.add([]string{"akshat"})
+	s.add([]string{"karan", otherContributors})
+
+	ids, rows, never := d.matrixView(s)
+	assert.Equal(t, []string{"akshat", "anand", "karan", otherContributors}, ids)
+	assert.Equal(t, []*matrixCell{{Count: 0}, {Count: 2}, {Self: true}, {Count: 1}}, rows[2].Cells)
+	assert.Equal(t, []string{"akshat & anand", "akshat & karan"}, never)
+}
+
+func TestIssueViews(t *testing.T) {
+	views := issueViews([]*changelogIssue{
+		{
+			Commits:      []*changelogCommit{{Contributors: []string{"Ext"}}, {Contributors: []string{"Ext"}}, {Contributors: []string{"Ext"}}},
+			Contributors: []string{"Ext"},
+		},
+		{
+			IssueID:      "#7",
+			Commits:      []*changelogCommit{{Contributors: []string{"Anand Shankar"}}},
+			Contributors: []string{"Anand Shankar"},
+		},
+		{
+			IssueID: "PAY-12",
+			Commits: []*changelogCommit{
+				{Contributors: []string{"Anand Shankar", "Karan Misra"}},
+				{Contributors: []string{"Karan Misra"}},
+			},
+			Contributors: []string{"Anand Shankar", "Karan Misra"},
+		},
+	})
+
+	assert.Equal(t, []*issueView{
+		{Title: "PAY-12", Commits: 2, Contributors: "Karan Misra (2), Anand Shankar (1)"},
+		{Title: "#7", Commits: 1, Contributors: "Anand Shankar (1)"},
+		{Title: unlinkedTitle, Commits: 3, Contributors: "Ext (3)"},
+	}, views)
+}
+
+func TestRepoReport(t *testing.T) {
+	d := trendData()
+	output := logRecord("b2", "Karan Misra <karan@beef.com>", 1551800000,
+		"Round refunds\n\nIssue-id: PAY-12\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n") +
+		logRecord("a1", "Anand Shankar <anand@beef.com>", 1551700000, "Fix <script> in CI\n")
+
+	_, restore := stubGit(map[string]string{
+		"log":           output,
+		"check-mailmap": "Anand Shankar <anand@beef.com>",
+	}, "")
+	defer restore()
+
+	dir := filepath.Join(t.TempDir(), "out")
+	path, err := d.repoReport(dir, "", false, 1, periodWeek)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, filepath.Join(dir, reportFile), path)
+
+	b, err := ioutil.ReadFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	html := string(b)
+
+	assert.Contains(t, html, "2 commits, generated 2019-03-04 10:00")
+	assert.Contains(t, html, "<tr><th>karan</th><td class=\"never\">&middot;</td><td class=\"n\">1</td><td class=\"self\"></td></tr>")
+	assert.Contains(t, html, "Never paired: akshat &amp; anand, akshat &amp; karan")
+	assert.Contains(t, html, "<tr><th>anand</th><td class=\"cell l0\" title=\"2019-03-04: 0/1 paired\"></td><td class=\"n\">0% (0/1)</td></tr>")
+	assert.Contains(t, html, "<tr><td>PAY-12</td><td class=\"n\">1</td><td>Anand Shankar (1), Karan Misra (1)</td></tr>")
+	assert.Contains(t, html, "<tr><td>Unlinked</td><td class=\"n\">1</td><td>Anand Shankar (1)</td></tr>")
+	assert.NotContains(t, html, "<script>")
+
+	// Nothing is loaded from elsewhere.
+	for _, external := range []string{"http://", "https://", "src=", "<link", "@import"} {
+		assert.False(t, strings.Contains(html, external), "report refers to %s", external)
+	}
+
+	_, err = d.repoReport("", "", false, 1, periodWeek)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no output directory", err.Error())
+	}
+}
+
+func TestWriteReportEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeReport(&buf, (&data{}).report(nil, periodMonth)))
+
+	assert.Contains(t, buf.String(), "<h2>Paired commits by month</h2>\n<p>No commits by devs in the roster.</p>")
+	assert.Contains(t, buf.String(), "<h2>Issues</h2>\n<p>No commits.</p>")
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// roleTrailers are the trailer keys used to record who drove and who
+// navigated while pairing.
+type roleTrailers struct {
+	Driver    string `json:"driver,omitempty"`
+	Navigator string `json:"navigator,omitempty"`
+}
+
+var defaultRoleTrailers = roleTrailers{
+	Driver:    "Pair-driver",
+	Navigator: "Pair-navigator",
+}
+
+// roleTrailers returns the trailer keys configured for the repo, falling
+// back to the defaults for the ones which are not.
+func (r *repo) roleTrailers() roleTrailers {
+	keys := defaultRoleTrailers
+	if r.RoleTrailers != nil {
+		if r.RoleTrailers.Driver != "" {
+			keys.Driver = r.RoleTrailers.Driver
+		}
+		if r.RoleTrailers.Navigator != "" {
+			keys.Navigator = r.RoleTrailers.Navigator
+		}
+	}
+	return keys
+}
+
+// pairRoles records who drove and navigated for a commit.
+type pairRoles struct {
+	driver     *dev
+	navigators []*dev
+	source     string
+}
+
+func (p *pairRoles) empty() bool {
+	return p == nil || (p.driver == nil && len(p.navigators) == 0)
+}
+
+// existingRoles parses the role trailers already present in the message.
+func existingRoles(msg string, keys roleTrailers) *pairRoles {
+	roles := &pairRoles{source: sourceTrailer}
+
+	scanner := bufio.NewScanner(strings.NewReader(msg))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, keys.Driver+":"):
+			name, email := nameEmail(line)
+			roles.driver = &dev{Name: name, Email: email}
+
+		case strings.HasPrefix(line, keys.Navigator+":"):
+			name, email := nameEmail(line)
+			roles.navigators = append(roles.navigators, &dev{Name: name, Email: email})
+		}
+	}
+
+	if roles.empty() {
+		return nil
+	}
+	return roles
+}
+
+// devRoles builds the roles for the given driver, with the rest of the
+// devs as navigators.
+func (d *data) devRoles(driverID string, devIDs []string, source string) *pairRoles {
+	driver := d.lookupDev(driverID)
+	if driver == nil {
+		return nil
+	}
+
+	roles := &pairRoles{driver: driver, source: source}
+	for _, id := range devIDs {
+		if dev := d.lookupDev(id); dev != nil && dev != driver {
+			roles.navigators = append(roles.navigators, dev)
+		}
+	}
+	return roles
+}
+
+// repoRoles returns the roles set for the repo devs. They do not apply
+// while a mob is in progress.
+func (d *data) repoRoles(repo *repo) *pairRoles {
+	if repo.Mob != nil {
+		return nil
+	}
+
+	roles := &pairRoles{source: sourceRepo}
+	if repo.Driver != "" {
+		roles.driver = d.lookupDev(repo.Driver)
+	}
+	for _, id := range d.expandDevIDs(repo.Navigators) {
+		if dev := d.lookupDev(id); dev != nil {
+			roles.navigators = append(roles.navigators, dev)
+		}
+	}
+
+	if roles.empty() {
+		return nil
+	}
+	return roles
+}
+
+// updateRepoRoles sets the driver and navigators working on the repo,
+// making sure they are among the repo devs. The driver can be given as a
+// team only if it has a single (active) member.
+func (d *data) updateRepoRoles(wd, driver string, navigators []string) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	var devIDs []string
+	if driver != "" {
+		if err := d.validateDevs([]string{driver}); err != nil {
+			return errors.Wrap(err, "dev ids validation failed")
+		}
+		ids := d.expandDevIDs([]string{driver})
+		if len(ids) != 1 {
+			return errors.Errorf("driver %s must be a single dev, not %d", driver, len(ids))
+		}
+		driver = ids[0]
+		devIDs = append(devIDs, driver)
+	}
+	devIDs = append(devIDs, navigators...)
+
+	if err := d.validateDevs(devIDs); err != nil {
+		return errors.Wrap(err, "dev ids validation failed")
+	}
+
+	for _, id := range devIDs {
+		if !contains(repo.Devs, id) {
+			repo.Devs = append(repo.Devs, id)
+		}
+	}
+
+	repo.Driver = driver
+	repo.Navigators = navigators
+
+	return nil
+}
+
+func (d *data) updateRepoRoleTrailers(wd string, keys *roleTrailers) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	for _, key := range []string{keys.Driver, keys.Navigator} {
+		if strings.ContainsAny(key, ": \n") {
+			return errors.Errorf("invalid trailer key %q", key)
+		}
+	}
+
+	repo.RoleTrailers = keys
+
+	return nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
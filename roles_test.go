@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoRoleTrailers(t *testing.T) {
+	assert.Equal(t, defaultRoleTrailers, new(repo).roleTrailers())
+
+	r := &repo{RoleTrailers: &roleTrailers{Navigator: "Navigator"}}
+	assert.Equal(t, roleTrailers{Driver: "Pair-driver", Navigator: "Navigator"}, r.roleTrailers())
+}
+
+func TestExistingRoles(t *testing.T) {
+	msg := "Line 1\n\nCo-authored-by: akshat <akshat@beef.com>\nPair-driver: akshat <akshat@beef.com>\nPair-navigator: Karan Misra <karan@beef.com>\n"
+
+	assert.Equal(t, &pairRoles{
+		driver:     &dev{Name: "akshat", Email: "akshat@beef.com"},
+		navigators: []*dev{{Name: "Karan Misra", Email: "karan@beef.com"}},
+		source:     sourceTrailer,
+	}, existingRoles(msg, defaultRoleTrailers))
+
+	assert.Nil(t, existingRoles(msg, roleTrailers{Driver: "Driver", Navigator: "Navigator"}))
+}
+
+func TestDataUpdateRepoRoles(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"ak": &dev{Name: "akshat", Email: "akshat@beef.com"},
+			"km": &dev{Name: "Karan Misra", Email: "karan@beef.com"},
+		},
+		Repos: map[string]*repo{
+			"/a": &repo{Devs: []string{"km"}},
+		},
+		Teams: map[string][]string{
+			"pay":  {"ak", "km"},
+			"solo": {"ak"},
+		},
+	}
+
+	assert.NoError(t, d.updateRepoRoles("/a", "ak", []string{"km"}))
+	assert.Equal(t, &repo{Devs: []string{"km", "ak"}, Driver: "ak", Navigators: []string{"km"}}, d.Repos["/a"])
+
+	assert.NoError(t, d.updateRepoRoles("/a", "", nil))
+	assert.Equal(t, &repo{Devs: []string{"km", "ak"}}, d.Repos["/a"])
+
+	err := d.updateRepoRoles("/a", "an", nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, "dev ids validation failed: no dev with id an found (did you mean ak?)", err.Error())
+	}
+
+	// A team only works as the driver when it is a single dev.
+	err = d.updateRepoRoles("/a", "@pay", nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, "driver @pay must be a single dev, not 2", err.Error())
+	}
+	assert.NoError(t, d.updateRepoRoles("/a", "@solo", []string{"km"}))
+	assert.Equal(t, "ak", d.Repos["/a"].Driver)
+
+	err = d.updateRepoRoles("/b", "ak", nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no repo with path /b found", err.Error())
+	}
+}
+
+func TestDataUpdateRepoRoleTrailers(t *testing.T) {
+	d := data{
+		Repos: map[string]*repo{
+			"/a": new(repo),
+		},
+	}
+
+	keys := &roleTrailers{Driver: "Driver", Navigator: "Navigator"}
+	assert.NoError(t, d.updateRepoRoleTrailers("/a", keys))
+	assert.Equal(t, keys, d.Repos["/a"].RoleTrailers)
+
+	err := d.updateRepoRoleTrailers("/a", &roleTrailers{Driver: "Pair driver"})
+	if assert.Error(t, err) {
+		assert.Equal(t, `invalid trailer key "Pair driver"`, err.Error())
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultSessionGap is how long a pair can go without committing before
+// their next commit is taken to start a new session.
+const defaultSessionGap = 45 * time.Minute
+
+// session is a stretch of time a group of devs spent working together,
+// from their first commit in it to the last.
+type session struct {
+	ids        []string
+	start, end time.Time
+}
+
+// duration is the time from the first commit to the last, plus a gap for
+// the work which went into the first commit.
+func (s *session) duration(gap time.Duration) time.Duration {
+	return s.end.Sub(s.start) + gap
+}
+
+// sessions clusters the commits made by more than one dev into sessions:
+// the commits of the same group of devs belong to the same session as long
+// as they are no more than gap apart. Commits by other groups in between
+// don't end a session. Sessions are sorted by their start.
+func (d *data) sessions(commits []*commit, gap time.Duration) []*session {
+	sorted := append([]*commit(nil), commits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].date.Before(sorted[j].date)
+	})
+
+	var (
+		sessions []*session
+		open     = make(map[string]*session)
+	)
+	for _, c := range sorted {
+		ids := d.contributors(c)
+		if len(ids) < 2 {
+			continue
+		}
+
+		key := strings.Join(ids, ",")
+		if s := open[key]; s != nil && c.date.Sub(s.end) <= gap {
+			s.end = c.date
+			continue
+		}
+
+		s := &session{ids: ids, start: c.date, end: c.date}
+		open[key] = s
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// pairTime is the time each pair of devs spent together, in all and by
+// week.
+type pairTime struct {
+	total    map[[2]string]time.Duration
+	sessions map[[2]string]int
+	weeks    map[time.Time]map[[2]string]time.Duration
+}
+
+// pairTimeOf credits the time of each session to every pair in it, in the
+// week the session started.
+func pairTimeOf(sessions []*session, gap time.Duration) *pairTime {
+	t := &pairTime{
+		total:    make(map[[2]string]time.Duration),
+		sessions: make(map[[2]string]int),
+		weeks:    make(map[time.Time]map[[2]string]time.Duration),
+	}
+
+	for _, s := range sessions {
+		week := periodStart(s.start, periodWeek)
+		if t.weeks[week] == nil {
+			t.weeks[week] = make(map[[2]string]time.Duration)
+		}
+
+		for i, a := range s.ids {
+			for _, b := range s.ids[i+1:] {
+				k := pairKey(a, b)
+				t.total[k] += s.duration(gap)
+				t.sessions[k]++
+				t.weeks[week][k] += s.duration(gap)
+			}
+		}
+	}
+	return t
+}
+
+// sortedPairs are the pairs in times, longest first.
+func sortedPairs(times map[[2]string]time.Duration) [][2]string {
+	pairs := make([][2]string, 0, len(times))
+	for k := range times {
+		pairs = append(pairs, k)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if times[pairs[i]] != times[pairs[j]] {
+			return times[pairs[i]] > times[pairs[j]]
+		}
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+func hours(d time.Duration) string {
+	return fmt.Sprintf("%.1fh", d.Hours())
+}
+
+func writePairTime(w io.Writer, t *pairTime) {
+	if len(t.total) == 0 {
+		fmt.Fprintln(w, "no paired commits")
+		return
+	}
+
+	fmt.Fprintln(w, "hours per pair:")
+	for _, k := range sortedPairs(t.total) {
+		unit := "sessions"
+		if t.sessions[k] == 1 {
+			unit = "session"
+		}
+		fmt.Fprintf(w, "  %s & %s: %s (%d %s)\n", k[0], k[1], hours(t.total[k]), t.sessions[k], unit)
+	}
+
+	weeks := make([]time.Time, 0, len(t.weeks))
+	for week := range t.weeks {
+		weeks = append(weeks, week)
+	}
+	sort.Slice(weeks, func(i, j int) bool {
+		return weeks[i].Before(weeks[j])
+	})
+
+	fmt.Fprintln(w, "hours per week:")
+	for _, week := range weeks {
+		var total time.Duration
+		for _, d := range t.weeks[week] {
+			total += d
+		}
+		fmt.Fprintf(w, "  %s: %s\n", periodLabel(week, periodWeek), hours(total))
+		for _, k := range sortedPairs(t.weeks[week]) {
+			fmt.Fprintf(w, "    %s & %s: %s\n", k[0], k[1], hours(t.weeks[week][k]))
+		}
+	}
+}
+
+// repoPairTime writes an estimate of the hours each pair spent together in
+// the current repo, or in every repo with allRepos.
+func (d *data) repoPairTime(w io.Writer, since string, allRepos bool, workers int, gap time.Duration) error {
+	if gap <= 0 {
+		return errors.Errorf("invalid session gap %s", gap)
+	}
+
+	commits, err := d.statsHistory(since, allRepos, workers)
+	if err != nil {
+		return err
+	}
+
+	writePairTime(w, pairTimeOf(d.sessions(commits, gap), gap))
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sessionCommits is a synthetic history over two weeks.
+func sessionCommits() []*commit {
+	var (
+		karan  = &dev{Name: "Karan Misra", Email: "karan@beef.com"}
+		anand  = &dev{Name: "Anand Shankar", Email: "anand@beef.com"}
+		akshat = &dev{Name: "Akshat Shah", Email: "akshat@beef.com"}
+	)
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2019, 3, day, hour, min, 0, 0, time.UTC)
+	}
+
+	return []*commit{
+		// Newest first, like git log.
+		{author: akshat, date: at(11, 9, 0), coAuthors: []*dev{karan, anand}},
+		{author: karan, date: at(4, 13, 0), coAuthors: []*dev{anand}},
+		{author: anand, date: at(4, 11, 10), coAuthors: []*dev{karan}},
+		{author: akshat, date: at(4, 10, 50), coAuthors: []*dev{karan}},
+		{author: karan, date: at(4, 10, 30), coAuthors: []*dev{anand}},
+		{author: karan, date: at(4, 10, 20)},
+		{author: karan, date: at(4, 10, 15), coAuthors: []*dev{akshat}},
+		{author: karan, date: at(4, 10, 0), coAuthors: []*dev{anand}},
+	}
+}
+
+func TestSessions(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2019, 3, day, hour, min, 0, 0, time.UTC)
+	}
+
+	sessions := trendData().sessions(sessionCommits(), 45*time.Minute)
+
+	assert.Equal(t, []*session{
+		// Interleaved commits by another pair, or a solo commit, don't
+		// end the session.
+		{ids: []string{"anand", "karan"}, start: at(4, 10, 0), end: at(4, 11, 10)},
+		{ids: []string{"akshat", "karan"}, start: at(4, 10, 15), end: at(4, 10, 50)},
+		// 1h50m after the last commit is a new session.
+		{ids: []string{"anand", "karan"}, start: at(4, 13, 0), end: at(4, 13, 0)},
+		{ids: []string{"akshat", "anand", "karan"}, start: at(11, 9, 0), end: at(11, 9, 0)},
+	}, sessions)
+
+	// With a longer gap the morning and afternoon are one session.
+	sessions = trendData().sessions(sessionCommits(), 2*time.Hour)
+	assert.Equal(t, at(4, 13, 0), sessions[0].end)
+	assert.Len(t, sessions, 3)
+
+	// A gap of exactly the limit stays in the session.
+	sessions = trendData().sessions(sessionCommits(), 110*time.Minute)
+	assert.Len(t, sessions, 3)
+
+	assert.Equal(t, 115*time.Minute, (&session{start: at(4, 10, 0), end: at(4, 11, 10)}).duration(45*time.Minute))
+}
+
+func TestPairTime(t *testing.T) {
+	gap := 45 * time.Minute
+	pt := pairTimeOf(trendData().sessions(sessionCommits(), gap), gap)
+
+	assert.Equal(t, map[[2]string]time.Duration{
+		{"anand", "karan"}:  115*time.Minute + 45*time.Minute + 45*time.Minute,
+		{"akshat", "karan"}: 80*time.Minute + 45*time.Minute,
+		{"akshat", "anand"}: 45 * time.Minute,
+	}, pt.total)
+	assert.Equal(t, map[[2]string]int{
+		{"anand", "karan"}:  3,
+		{"akshat", "karan"}: 2,
+		{"akshat", "anand"}: 1,
+	}, pt.sessions)
+
+	var buf bytes.Buffer
+	writePairTime(&buf, pt)
+	assert.Equal(t, "hours per pair:\n"+
+		"  anand & karan: 3.4h (3 sessions)\n"+
+		"  akshat & karan: 2.1h (2 sessions)\n"+
+		"  akshat & anand: 0.8h (1 session)\n"+
+		"hours per week:\n"+
+		"  2019-03-04: 4.0h\n"+
+		"    anand & karan: 2.7h\n"+
+		"    akshat & karan: 1.3h\n"+
+		"  2019-03-11: 2.2h\n"+
+		"    akshat & anand: 0.8h\n"+
+		"    akshat & karan: 0.8h\n"+
+		"    anand & karan: 0.8h\n", buf.String())
+
+	buf.Reset()
+	writePairTime(&buf, pairTimeOf(nil, gap))
+	assert.Equal(t, "no paired commits\n", buf.String())
+}
+
+func TestRepoPairTime(t *testing.T) {
+	output := logRecord("b2", "Karan Misra <karan@beef.com>", 1551700600,
+		"Line 2\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n") +
+		logRecord("a1", "Karan Misra <karan@beef.com>", 1551700000,
+			"Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n")
+
+	calls, restore := stubGit(map[string]string{
+		"log":           output,
+		"check-mailmap": "Anand Shankar <anand@beef.com>",
+	}, "")
+	defer restore()
+
+	var buf bytes.Buffer
+	err := trendData().repoPairTime(&buf, "", false, 1, 30*time.Minute)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "log "+historyFormat+" --no-merges", (*calls)[0])
+		assert.Contains(t, buf.String(), "  anand & karan: 0.7h (1 session)\n")
+	}
+
+	err = trendData().repoPairTime(&buf, "", false, 1, 0)
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid session gap 0s", err.Error())
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// mailmapBatch is how many idents are mapped per git check-mailmap call.
+const mailmapBatch = 100
+
+// applyMailmap maps the co-authors of commits through the .mailmap of the
+// repo at repoPath, like git already does for the authors.
+func applyMailmap(repoPath string, commits []*commit) error {
+	var idents []string
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		for _, dev := range c.coAuthors {
+			if ident := dev.String(); !seen[ident] {
+				seen[ident] = true
+				idents = append(idents, ident)
+			}
+		}
+	}
+
+	mapped := make(map[string]*dev)
+	for start := 0; start < len(idents); start += mailmapBatch {
+		end := start + mailmapBatch
+		if end > len(idents) {
+			end = len(idents)
+		}
+		batch := idents[start:end]
+
+		var args []string
+		if repoPath != "" {
+			args = append(args, "-C", repoPath)
+		}
+		args = append(args, "check-mailmap")
+		args = append(args, batch...)
+
+		output, err := gitRun(args...)
+		if err != nil {
+			return errors.Wrap(err, "check mailmap failed")
+		}
+
+		lines := strings.Split(output, "\n")
+		if len(lines) != len(batch) {
+			return errors.Errorf("check mailmap returned %d idents for %d", len(lines), len(batch))
+		}
+		for i, line := range lines {
+			name, email := nameEmail(line)
+			mapped[batch[i]] = &dev{Name: name, Email: email}
+		}
+	}
+
+	for _, c := range commits {
+		for i, dev := range c.coAuthors {
+			if m := mapped[dev.String()]; m != nil && m.Email != "" {
+				c.coAuthors[i] = m
+			}
+		}
+	}
+	return nil
+}
+
+// credit is what a dev contributed to, counting the commits they were the
+// author or a co-author of.
+type credit struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Commits    int    `json:"commits"`
+	Authored   int    `json:"authored"`
+	CoAuthored int    `json:"coAuthored"`
+}
+
+// shortlog credits each commit to its author and every co-author, most
+// credited first.
+func (d *data) shortlog(commits []*commit) []*credit {
+	credits := make(map[string]*credit)
+
+	creditFor := func(dev *dev) *credit {
+		id := d.contributorID(dev.Email)
+		if c := credits[id]; c != nil {
+			return c
+		}
+
+		c := &credit{ID: id, Name: dev.Name, Email: dev.Email}
+		if rdev := d.lookupDev(id); rdev != nil {
+			c.Name, c.Email = rdev.Name, rdev.Email
+		}
+		credits[id] = c
+		return c
+	}
+
+	for _, c := range commits {
+		author := creditFor(c.author)
+		author.Commits++
+		author.Authored++
+
+		for _, dev := range c.coAuthors {
+			// Crediting yourself as co-author does not count twice.
+			if cr := creditFor(dev); cr != author {
+				cr.Commits++
+				cr.CoAuthored++
+			}
+		}
+	}
+
+	list := make([]*credit, 0, len(credits))
+	for _, c := range credits {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Commits != list[j].Commits {
+			return list[i].Commits > list[j].Commits
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+func writeShortlog(w io.Writer, credits []*credit, asJSON bool) error {
+	if asJSON {
+		if credits == nil {
+			credits = []*credit{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(credits)
+	}
+
+	for _, c := range credits {
+		fmt.Fprintf(w, "%6d\t%s (%d authored, %d co-authored)\n", c.Commits, (&dev{Name: c.Name, Email: c.Email}), c.Authored, c.CoAuthored)
+	}
+	return nil
+}
+
+// repoShortlog writes the shortlog of the current repo for the commits in
+// revs (all of HEAD when empty) between since and until.
+func (d *data) repoShortlog(w io.Writer, revs []string, since, until string, asJSON bool) error {
+	args, err := historyArgs(since, until, false)
+	if err != nil {
+		return err
+	}
+	args = append(args, revs...)
+
+	commits, err := readHistory("", args...)
+	if err != nil {
+		return err
+	}
+
+	return writeShortlog(w, d.shortlog(commits), asJSON)
+}
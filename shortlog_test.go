@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMailmap(t *testing.T) {
+	commits := []*commit{
+		{coAuthors: []*dev{{Name: "A", Email: "anand@old.com"}, {Name: "Ext", Email: "ext@corp.com"}}},
+		{coAuthors: []*dev{{Name: "A", Email: "anand@old.com"}}},
+		{},
+	}
+
+	calls, restore := stubGit(map[string]string{
+		"check-mailmap": "Anand Shankar <anand@beef.com>\nExt <ext@corp.com>",
+	}, "")
+	defer restore()
+
+	if !assert.NoError(t, applyMailmap("/a", commits)) {
+		return
+	}
+
+	assert.Equal(t, []string{"-C /a check-mailmap A <anand@old.com> Ext <ext@corp.com>"}, *calls)
+	assert.Equal(t, []*dev{{Name: "Anand Shankar", Email: "anand@beef.com"}, {Name: "Ext", Email: "ext@corp.com"}}, commits[0].coAuthors)
+	assert.Equal(t, []*dev{{Name: "Anand Shankar", Email: "anand@beef.com"}}, commits[1].coAuthors)
+}
+
+func TestShortlog(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan": {Name: "Karan Misra", Email: "karan@beef.com", Emails: []string{"karan@home.com"}},
+			"anand": {Name: "Anand Shankar", Email: "anand@beef.com"},
+		},
+	}
+
+	output := logRecord("c3", "Karan <karan@home.com>", 1551700000,
+		"Line 3\n\nCo-authored-by: A <anand@old.com>\nCo-authored-by: Karan Misra <karan@beef.com>\n") +
+		logRecord("b2", "Anand Shankar <anand@beef.com>", 1551600000,
+			"Line 2\n\nCo-authored-by: Ext <ext@corp.com>\n") +
+		logRecord("a1", "Karan Misra <karan@beef.com>", 1551500000, "Line 1\n")
+
+	oldGitRun := gitRun
+	defer func() {
+		gitRun = oldGitRun
+	}()
+
+	var calls [][]string
+	gitRun = func(args ...string) (string, error) {
+		calls = append(calls, args)
+		if args[0] == "check-mailmap" {
+			return "Anand Shankar <anand@beef.com>\nKaran Misra <karan@beef.com>\nExt <ext@corp.com>", nil
+		}
+		return output, nil
+	}
+	oldNow := now
+	defer func() {
+		now = oldNow
+	}()
+	now = func() time.Time {
+		return time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, d.repoShortlog(&buf, []string{"v1..v2"}, "30d", "2019-03-01", false)) {
+		return
+	}
+
+	assert.Equal(t, []string{"log", historyFormat, "--no-merges", "--since=2019-02-02T10:00:00Z",
+		"--until=" + time.Date(2019, 3, 1, 0, 0, 0, 0, time.Local).Format(time.RFC3339), "v1..v2"}, calls[0])
+	assert.Equal(t, ""+
+		"     2\tAnand Shankar <anand@beef.com> (1 authored, 1 co-authored)\n"+
+		"     2\tKaran Misra <karan@beef.com> (2 authored, 0 co-authored)\n"+
+		"     1\tExt <ext@corp.com> (0 authored, 1 co-authored)\n", buf.String())
+
+	buf.Reset()
+	if !assert.NoError(t, d.repoShortlog(&buf, nil, "", "", true)) {
+		return
+	}
+	assert.Equal(t, `[
+  {
+    "id": "anand",
+    "name": "Anand Shankar",
+    "email": "anand@beef.com",
+    "commits": 2,
+    "authored": 1,
+    "coAuthored": 1
+  },
+  {
+    "id": "karan",
+    "name": "Karan Misra",
+    "email": "karan@beef.com",
+    "commits": 2,
+    "authored": 2,
+    "coAuthored": 0
+  },
+  {
+    "id": "ext@corp.com",
+    "name": "Ext",
+    "email": "ext@corp.com",
+    "commits": 1,
+    "authored": 0,
+    "coAuthored": 1
+  }
+]
+`, buf.String())
+}
+
+func TestWriteShortlogEmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeShortlog(&buf, nil, true))
+	assert.Equal(t, "[]\n", buf.String())
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const signOffPrefix = "Signed-off-by:"
+
+// existingSignOffs parses the Signed-off-by trailers in msg, like the one
+// added by git commit -s, dropping the ones whose email was already seen.
+func existingSignOffs(msg string) []*dev {
+	var devs []*dev
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(msg))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if !strings.HasPrefix(line, signOffPrefix) {
+			continue
+		}
+
+		name, email := nameEmail(line)
+		if email == "" || seen[emailKey(email)] {
+			continue
+		}
+		seen[emailKey(email)] = true
+		devs = append(devs, &dev{Name: name, Email: email})
+	}
+
+	return devs
+}
+
+// signOffs adds sign-offs for the author and the co-authors who opted in
+// to the existing ones, unless they already signed off.
+func (d *data) signOffs(existing []*dev, author *dev, coAuthors []*attributedDev) []*dev {
+	signers := []*dev{author}
+	for _, a := range coAuthors {
+		if _, dev := d.lookupDevByEmail(a.dev.Email); dev != nil && dev.SignOff {
+			signers = append(signers, a.dev)
+		}
+	}
+
+	signOffs := existing
+	for _, signer := range signers {
+		var signed bool
+		for _, s := range signOffs {
+			if sameEmail(s.Email, signer.Email) {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			signOffs = append(signOffs, signer)
+		}
+	}
+	return signOffs
+}
+
+func (d *data) updateRepoSignOff(wd string, signOff bool) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	repo.SignOff = signOff
+
+	return nil
+}
+
+func (d *data) updateDevSignOff(id string, signOff bool) error {
+	dev := d.lookupDev(id)
+	if dev == nil {
+		return errors.Errorf("no dev with id %s found", id)
+	}
+
+	dev.SignOff = signOff
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExistingSignOffs(t *testing.T) {
+	msg := "Line 1\n\n" +
+		"Signed-off-by: Karan Misra <karan@beef.com>\n" +
+		"Co-authored-by: Anand Shankar <anand@beef.com>\n" +
+		"Signed-off-by: Karan Misra <Karan+xp@beef.com>\n" +
+		"Signed-off-by: Anand Shankar <anand@beef.com>\n"
+
+	assert.Equal(t, []*dev{
+		{Name: "Karan Misra", Email: "karan@beef.com"},
+		{Name: "Anand Shankar", Email: "anand@beef.com"},
+	}, existingSignOffs(msg))
+}
+
+func TestUpdateRepoSignOff(t *testing.T) {
+	d := data{
+		Repos: map[string]*repo{
+			"/a": new(repo),
+		},
+	}
+
+	assert.NoError(t, d.updateRepoSignOff("/a/b", true))
+	assert.True(t, d.Repos["/a"].SignOff)
+
+	err := d.updateRepoSignOff("/b", true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no repo with path /b found", err.Error())
+	}
+}
+
+func TestUpdateDevSignOff(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"neha": {Name: "Neha Jain", Email: "neha@beef.com", Aliases: []string{"nj"}},
+		},
+	}
+
+	assert.NoError(t, d.updateDevSignOff("nj", true))
+	assert.True(t, d.Devs["neha"].SignOff)
+
+	assert.NoError(t, d.updateDevSignOff("neha", false))
+	assert.False(t, d.Devs["neha"].SignOff)
+
+	err := d.updateDevSignOff("karan", true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no dev with id karan found", err.Error())
+	}
+}
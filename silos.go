@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	defaultSiloMonths = 6
+	defaultSiloDepth  = 2
+)
+
+// area is the part of the code under a directory, along with who worked
+// on it.
+type area struct {
+	dir          string
+	commits      int
+	contributors []string
+
+	// groups are the distinct sets of devs the commits were made by.
+	groups map[string]bool
+}
+
+// silo tells if only one dev, or one pair always working together, has
+// touched the area.
+func (a *area) silo() bool {
+	return len(a.groups) == 1 && len(a.contributors) <= 2
+}
+
+// fileDirs are the directories of file, up to depth levels deep. Files at
+// the top level are under ".".
+func fileDirs(file string, depth int) []string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return []string{"."}
+	}
+
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+
+	dirs := make([]string, len(parts))
+	for i := range parts {
+		dirs[i] = strings.Join(parts[:i+1], "/")
+	}
+	return dirs
+}
+
+// areas are the directories (up to depth levels deep) changed by commits,
+// sorted by path.
+func (d *data) areas(commits []*commit, depth int) []*area {
+	byDir := make(map[string]*area)
+
+	for _, c := range commits {
+		ids := d.contributors(c)
+		group := strings.Join(ids, ",")
+
+		seen := make(map[string]bool)
+		for _, file := range c.files {
+			for _, dir := range fileDirs(file, depth) {
+				if seen[dir] {
+					continue
+				}
+				seen[dir] = true
+
+				a := byDir[dir]
+				if a == nil {
+					a = &area{dir: dir, groups: make(map[string]bool)}
+					byDir[dir] = a
+				}
+				a.commits++
+				a.groups[group] = true
+				for _, id := range ids {
+					if !contains(a.contributors, id) {
+						a.contributors = append(a.contributors, id)
+					}
+				}
+			}
+		}
+	}
+
+	areas := make([]*area, 0, len(byDir))
+	for _, a := range byDir {
+		sort.Strings(a.contributors)
+		areas = append(areas, a)
+	}
+	sort.Slice(areas, func(i, j int) bool {
+		return areas[i].dir < areas[j].dir
+	})
+	return areas
+}
+
+func writeAreas(w io.Writer, areas []*area, all bool) {
+	var listed []*area
+	for _, a := range areas {
+		if all || a.silo() {
+			listed = append(listed, a)
+		}
+	}
+	if len(listed) == 0 {
+		fmt.Fprintln(w, "no silos found")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIRECTORY\tCONTRIBUTORS\tCOMMITS\tWHO")
+	for _, a := range listed {
+		who := strings.Join(a.contributors, ", ")
+		if len(a.contributors) == 2 && a.silo() {
+			who += " (always together)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", a.dir, len(a.contributors), a.commits, who)
+	}
+	tw.Flush()
+}
+
+// repoSilos writes the areas of the current repo which only one dev (or
+// pair) worked on in the last months, or every area with all.
+func (d *data) repoSilos(w io.Writer, months, depth int, all bool) error {
+	if months < 1 {
+		months = defaultSiloMonths
+	}
+	if depth < 1 {
+		depth = defaultSiloDepth
+	}
+
+	args, err := historyArgs(fmt.Sprintf("%dm", months), "", false)
+	if err != nil {
+		return err
+	}
+	commits, err := readHistory("", append(args, "--name-only")...)
+	if err != nil {
+		return err
+	}
+
+	writeAreas(w, d.areas(commits, depth), all)
+
+	return nil
+}
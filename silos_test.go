@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileDirs(t *testing.T) {
+	assert.Equal(t, []string{"."}, fileDirs("README.md", 2))
+	assert.Equal(t, []string{"api"}, fileDirs("api/main.go", 2))
+	assert.Equal(t, []string{"api", "api/pay"}, fileDirs("api/pay/refund/refund.go", 2))
+	assert.Equal(t, []string{"api", "api/pay", "api/pay/refund"}, fileDirs("api/pay/refund/refund.go", 3))
+}
+
+func TestSilos(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan":  {Name: "Karan Misra", Email: "karan@beef.com"},
+			"anand":  {Name: "Anand Shankar", Email: "anand@beef.com"},
+			"akshat": {Name: "Akshat Shah", Email: "akshat@beef.com"},
+		},
+	}
+
+	output := logRecord("d4", "Akshat Shah <akshat@beef.com>", 1551800000, "Line 4\n",
+		"web/app.js") +
+		logRecord("c3", "Karan Misra <karan@beef.com>", 1551700000,
+			"Line 3\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+			"api/pay/refund.go", "api/pay/charge.go", "web/app.js") +
+		logRecord("b2", "Anand Shankar <anand@beef.com>", 1551600000,
+			"Line 2\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+			"api/pay/refund.go") +
+		logRecord("a1", "Karan Misra <karan@beef.com>", 1551500000, "Line 1\n",
+			"api/auth/login.go", "README.md")
+
+	tests := []struct {
+		all    bool
+		output string
+	}{
+		{
+			output: "" +
+				"DIRECTORY  CONTRIBUTORS  COMMITS  WHO\n" +
+				".          1             1        karan\n" +
+				"api/auth   1             1        karan\n" +
+				"api/pay    2             2        anand, karan (always together)\n",
+		},
+		{
+			all: true,
+			output: "" +
+				"DIRECTORY  CONTRIBUTORS  COMMITS  WHO\n" +
+				".          1             1        karan\n" +
+				"api        2             3        anand, karan\n" +
+				"api/auth   1             1        karan\n" +
+				"api/pay    2             2        anand, karan (always together)\n" +
+				"web        3             2        akshat, anand, karan\n",
+		},
+	}
+
+	for _, tt := range tests {
+		calls, restore := stubGit(map[string]string{
+			"log":           output,
+			"check-mailmap": "Anand Shankar <anand@beef.com>\nKaran Misra <karan@beef.com>",
+		}, "")
+
+		var buf bytes.Buffer
+		err := d.repoSilos(&buf, 3, 2, tt.all)
+		restore()
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "log "+historyFormat+" --no-merges --since=2018-12-04T10:00:00Z --name-only", (*calls)[0])
+			assert.Equal(t, tt.output, buf.String())
+		}
+	}
+}
+
+func TestNoSilos(t *testing.T) {
+	var buf bytes.Buffer
+	writeAreas(&buf, []*area{
+		{dir: "api", commits: 2, contributors: []string{"anand", "karan"}, groups: map[string]bool{"anand": true, "karan": true}},
+	}, false)
+
+	assert.Equal(t, "no silos found\n", buf.String())
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// pairStats counts the commits of each dev, and the commits each pair of
+// devs shared.
+type pairStats struct {
+	commits map[string]int
+	pairs   map[[2]string]int
+}
+
+func newPairStats() *pairStats {
+	return &pairStats{
+		commits: make(map[string]int),
+		pairs:   make(map[[2]string]int),
+	}
+}
+
+func pairKey(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// add counts a commit by the given devs.
+func (s *pairStats) add(ids []string) {
+	for i, a := range ids {
+		s.commits[a]++
+		for _, b := range ids[i+1:] {
+			s.pairs[pairKey(a, b)]++
+		}
+	}
+}
+
+func (s *pairStats) pair(a, b string) int {
+	return s.pairs[pairKey(a, b)]
+}
+
+func (d *data) pairStats(commits []*commit) *pairStats {
+	s := newPairStats()
+	for _, c := range commits {
+		s.add(d.contributors(c))
+	}
+	return s
+}
+
+// otherContributors is the pair matrix row and column grouping everyone
+// missing from the roster, so each outside email does not add its own.
+const otherContributors = "(others)"
+
+// matrixStats are the stats shown in the pair matrix, with the
+// contributors missing from the roster grouped as otherContributors.
+func (d *data) matrixStats(commits []*commit) *pairStats {
+	s := newPairStats()
+	for _, c := range commits {
+		var ids []string
+		for _, id := range d.contributors(c) {
+			if d.Devs[id] == nil {
+				id = otherContributors
+			}
+			if !contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		s.add(ids)
+	}
+	return s
+}
+
+// statsDevs are the devs to report on: the active roster along with
+// everyone who shows up in s, with otherContributors last.
+func (d *data) statsDevs(s *pairStats) []string {
+	var ids []string
+	for id, dev := range d.Devs {
+		if !dev.Retired {
+			ids = append(ids, id)
+		}
+	}
+	for id := range s.commits {
+		if id != otherContributors && !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	if s.commits[otherContributors] != 0 {
+		ids = append(ids, otherContributors)
+	}
+	return ids
+}
+
+// neverPaired tells whether a and b are reported as never having paired.
+// Not pairing with outside contributors is not worth reporting.
+func neverPaired(s *pairStats, a, b string) bool {
+	return s.pair(a, b) == 0 && a != otherContributors && b != otherContributors
+}
+
+// writePairMatrix writes the commits shared by each pair of devs, with
+// pairs which never worked together marked with a dot and listed below.
+// s is expected to come from matrixStats.
+func (d *data) writePairMatrix(w io.Writer, s *pairStats) {
+	ids := d.statsDevs(s)
+
+	width := 1
+	for _, id := range ids {
+		if len(id) > width {
+			width = len(id)
+		}
+	}
+
+	fmt.Fprintf(w, "%-*s", width, "")
+	for _, id := range ids {
+		fmt.Fprintf(w, " %*s", width, id)
+	}
+	fmt.Fprintln(w)
+
+	var never []string
+	for i, a := range ids {
+		fmt.Fprintf(w, "%-*s", width, a)
+		for j, b := range ids {
+			cell := "-"
+			if i != j {
+				cell = "."
+				if n := s.pair(a, b); n != 0 {
+					cell = fmt.Sprint(n)
+				} else if i < j && neverPaired(s, a, b) {
+					never = append(never, a+" & "+b)
+				}
+			}
+			fmt.Fprintf(w, " %*s", width, cell)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(never) != 0 {
+		fmt.Fprintf(w, "\nnever paired: %s\n", strings.Join(never, ", "))
+	}
+}
+
+// defaultStatsWorkers is how many repos are read at a time with
+// --all-repos.
+const defaultStatsWorkers = 4
+
+// statsHistory reads the commits to report on from the current repo, or
+// from every repo with allRepos, leaving out those filtered out.
+func (d *data) statsHistory(since string, allRepos bool, workers int) ([]*commit, error) {
+	filter, err := d.StatsFilters.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := historyArgs(since, "", filter.merges)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*commit
+	if allRepos {
+		commits, err = d.allRepoHistory(args, workers)
+	} else {
+		commits, err = readHistory("", args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return filter.apply(commits), nil
+}
+
+// repoStats are the stats of the current repo, or of every repo with
+// allRepos.
+func (d *data) repoStats(since string, allRepos bool, workers int) (*pairStats, error) {
+	commits, err := d.statsHistory(since, allRepos, workers)
+	if err != nil {
+		return nil, err
+	}
+	return d.pairStats(commits), nil
+}
+
+// allRepoHistory reads the history of every repo, with at most workers of
+// them being read at a time. Repos whose path no longer exists or whose
+// history cannot be read (like an empty repo) are skipped with a warning,
+// failing only when none could be read.
+func (d *data) allRepoHistory(args []string, workers int) ([]*commit, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var paths []string
+	for path := range d.Repos {
+		if _, err := os.Stat(path); err != nil {
+			log.Printf("skipping repo %s: %v", path, err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	type result struct {
+		path    string
+		commits []*commit
+		err     error
+	}
+
+	jobs := make(chan string)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				commits, err := readHistory(path, args...)
+				results <- result{path: path, commits: commits, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, path := range paths {
+			jobs <- path
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		commits []*commit
+		failed  int
+	)
+	for r := range results {
+		if r.err != nil {
+			log.Printf("skipping repo %s: %v", r.path, r.err)
+			failed++
+			continue
+		}
+		commits = append(commits, r.commits...)
+	}
+
+	if failed != 0 && failed == len(paths) {
+		return nil, errors.Errorf("could not read any of the %d repos", failed)
+	}
+	return commits, nil
+}
+
+// writeStatsSummary writes the commits per dev and per pair, most first.
+func (d *data) writeStatsSummary(w io.Writer, s *pairStats) {
+	ids := make([]string, 0, len(s.commits))
+	for id := range s.commits {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if s.commits[ids[i]] != s.commits[ids[j]] {
+			return s.commits[ids[i]] > s.commits[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	pairs := make([][2]string, 0, len(s.pairs))
+	for k := range s.pairs {
+		pairs = append(pairs, k)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if s.pairs[pairs[i]] != s.pairs[pairs[j]] {
+			return s.pairs[pairs[i]] > s.pairs[pairs[j]]
+		}
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	fmt.Fprintln(w, "commits per dev:")
+	for _, id := range ids {
+		fmt.Fprintf(w, "  %s: %d\n", id, s.commits[id])
+	}
+	fmt.Fprintln(w, "commits per pair:")
+	for _, k := range pairs {
+		fmt.Fprintf(w, "  %s & %s: %d\n", k[0], k[1], s.pairs[k])
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairStats(t *testing.T) {
+	s := newPairStats()
+	s.add([]string{"anand", "karan"})
+	s.add([]string{"akshat", "anand", "karan"})
+	s.add([]string{"karan"})
+
+	assert.Equal(t, 2, s.pair("karan", "anand"))
+	assert.Equal(t, 1, s.pair("akshat", "karan"))
+	assert.Equal(t, 0, s.pair("akshat", "neha"))
+	assert.Equal(t, map[string]int{"akshat": 1, "anand": 2, "karan": 3}, s.commits)
+}
+
+func TestStatsPairs(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"karan":  {Name: "Karan Misra", Email: "karan@beef.com"},
+			"anand":  {Name: "Anand Shankar", Email: "anand@beef.com"},
+			"akshat": {Name: "Akshat Shah", Email: "akshat@beef.com"},
+			"rohan":  {Name: "Rohan Das", Email: "rohan@beef.com", Retired: true},
+		},
+	}
+
+	output := logRecord("c3", "Karan Misra <karan@beef.com>", 1551700000,
+		"Line 3\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n") +
+		logRecord("b2", "Anand Shankar <anand@beef.com>", 1551600000,
+			"Line 2\n\nCo-authored-by: Karan Misra <KARAN@beef.com>\nCo-authored-by: Ext <ext@corp.com>\nCo-authored-by: Vendor <dev@vendor.io>\n") +
+		logRecord("a1", "Akshat Shah <akshat@beef.com>", 1551500000, "Line 1\n")
+
+	calls, restore := stubGit(map[string]string{
+		"log":           output,
+		"check-mailmap": "Anand Shankar <anand@beef.com>\nKaran Misra <KARAN@beef.com>\nExt <ext@corp.com>\nVendor <dev@vendor.io>",
+	}, "")
+	defer restore()
+
+	commits, err := d.statsHistory("30d", false, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	d.writePairMatrix(&buf, d.matrixStats(commits))
+
+	assert.Equal(t, "log "+historyFormat+" --no-merges --since=2019-02-02T10:00:00Z", (*calls)[0])
+	assert.Equal(t, ""+
+		"           akshat    anand    karan (others)\n"+
+		"akshat          -        .        .        .\n"+
+		"anand           .        -        2        1\n"+
+		"karan           .        2        -        1\n"+
+		"(others)        .        1        1        -\n"+
+		"\n"+
+		"never paired: akshat & anand, akshat & karan\n", buf.String())
+
+	_, err = d.statsHistory("soon", false, 0)
+	if assert.Error(t, err) {
+		assert.Equal(t, `invalid time "soon"`, err.Error())
+	}
+}
+
+func TestAllRepoHistory(t *testing.T) {
+	var dirs []string
+	for i := 0; i < 5; i++ {
+		dir, err := ioutil.TempDir("", "xp")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+		dirs = append(dirs, dir)
+	}
+
+	d := data{
+		Devs: map[string]*dev{
+			"karan": {Name: "Karan Misra", Email: "karan@beef.com"},
+			"anand": {Name: "Anand Shankar", Email: "anand@beef.com"},
+		},
+		Repos: map[string]*repo{
+			"/no/longer/there": new(repo),
+		},
+	}
+	for _, dir := range dirs {
+		d.Repos[dir] = new(repo)
+	}
+
+	output := logRecord("a1", "Karan Misra <karan@beef.com>", 1551700000,
+		"Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n")
+
+	var (
+		mu               sync.Mutex
+		running, maxRuns int
+		paths            []string
+		failing          string
+	)
+
+	oldGitRun := gitRun
+	defer func() {
+		gitRun = oldGitRun
+	}()
+	gitRun = func(args ...string) (string, error) {
+		if args[2] == "check-mailmap" {
+			return "Anand Shankar <anand@beef.com>", nil
+		}
+
+		mu.Lock()
+		running++
+		if running > maxRuns {
+			maxRuns = running
+		}
+		paths = append(paths, args[1])
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if args[1] == failing {
+			return "", errors.New("failed")
+		}
+		return output, nil
+	}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	commits, err := d.allRepoHistory(nil, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := d.pairStats(commits)
+
+	assert.Equal(t, map[string]int{"karan": 5, "anand": 5}, s.commits)
+	assert.Equal(t, 5, s.pair("anand", "karan"))
+	assert.ElementsMatch(t, dirs, paths)
+	assert.True(t, maxRuns <= 2, "%d repos read at a time", maxRuns)
+	assert.Contains(t, logs.String(), "skipping repo /no/longer/there")
+
+	logs.Reset()
+	failing = dirs[3]
+	commits, err = d.allRepoHistory(nil, 2)
+	if assert.NoError(t, err) {
+		assert.Len(t, commits, 4)
+		assert.Contains(t, logs.String(), "skipping repo "+dirs[3]+": read history failed: failed")
+	}
+
+	d.Repos = map[string]*repo{dirs[3]: new(repo)}
+	_, err = d.allRepoHistory(nil, 2)
+	if assert.Error(t, err) {
+		assert.Equal(t, "could not read any of the 1 repos", err.Error())
+	}
+}
+
+func TestAllRepoHistoryEmptyRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	empty, err := ioutil.TempDir("", "xp")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(empty)
+	if err := exec.Command("git", "init", "-q", empty).Run(); err != nil {
+		t.Skipf("git init failed: %v", err)
+	}
+
+	other, err := ioutil.TempDir("", "xp")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(other)
+
+	d := data{Repos: map[string]*repo{empty: new(repo), other: new(repo)}}
+
+	// Only the empty repo runs the real git, which exits with 128 as it
+	// has no commits yet.
+	realGitRun := gitRun
+	defer func() {
+		gitRun = realGitRun
+	}()
+	gitRun = func(args ...string) (string, error) {
+		if args[1] == empty {
+			return realGitRun(args...)
+		}
+		if args[2] == "check-mailmap" {
+			return "Karan Misra <karan@beef.com>", nil
+		}
+		return logRecord("a1", "Karan Misra <karan@beef.com>", 1551700000, "Line 1\n"), nil
+	}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	commits, err := d.allRepoHistory([]string{"--no-merges"}, 2)
+	if assert.NoError(t, err) {
+		assert.Len(t, commits, 1)
+		assert.Contains(t, logs.String(), "skipping repo "+empty)
+	}
+}
+
+func TestWriteStatsSummary(t *testing.T) {
+	s := newPairStats()
+	s.add([]string{"anand", "karan"})
+	s.add([]string{"akshat", "karan"})
+	s.add([]string{"anand", "karan"})
+
+	var buf bytes.Buffer
+	(&data{}).writeStatsSummary(&buf, s)
+
+	assert.Equal(t, ""+
+		"commits per dev:\n"+
+		"  karan: 3\n"+
+		"  anand: 2\n"+
+		"  akshat: 1\n"+
+		"commits per pair:\n"+
+		"  anand & karan: 2\n"+
+		"  akshat & karan: 1\n", buf.String())
+}
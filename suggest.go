@@ -0,0 +1,125 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+const maxSuggestions = 3
+
+// suggestDevs returns the ids of the devs whose id, name or email are
+// close to the given (unknown) dev id, closest first.
+func (d *data) suggestDevs(id string) []string {
+	id = strings.ToLower(strings.TrimLeft(id, "+-@"))
+	if id == "" {
+		return nil
+	}
+
+	// Allow roughly one typo for every three characters typed.
+	maxDist := 1 + len(id)/3
+
+	dists := make(map[string]int)
+	for devID, dev := range d.Devs {
+		candidates := []string{devID, dev.Name, dev.Handle}
+		candidates = append(candidates, dev.Aliases...)
+		candidates = append(candidates, strings.Fields(dev.Name)...)
+		if i := strings.Index(dev.Email, "@"); i != -1 {
+			candidates = append(candidates, dev.Email[:i])
+		}
+
+		best := -1
+		for _, c := range candidates {
+			c = strings.ToLower(c)
+			if c == "" {
+				continue
+			}
+
+			dist := editDistance(id, c)
+			if strings.HasPrefix(c, id) {
+				// A prefix of something is as good as a single typo.
+				dist = 1
+			}
+			if best == -1 || dist < best {
+				best = dist
+			}
+		}
+
+		if best != -1 && best <= maxDist {
+			dists[devID] = best
+		}
+	}
+
+	ids := make([]string, 0, len(dists))
+	for devID := range dists {
+		ids = append(ids, devID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if dists[ids[i]] != dists[ids[j]] {
+			return dists[ids[i]] < dists[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	if len(ids) > maxSuggestions {
+		ids = ids[:maxSuggestions]
+	}
+	return ids
+}
+
+// didYouMean returns a hint to be appended to errors about the unknown
+// dev id, or an empty string if there is nothing to suggest.
+func (d *data) didYouMean(id string) string {
+	ids := d.suggestDevs(id)
+	switch len(ids) {
+	case 0:
+		return ""
+	case 1:
+		return " (did you mean " + ids[0] + "?)"
+	default:
+		return " (did you mean " + strings.Join(ids[:len(ids)-1], ", ") + " or " + ids[len(ids)-1] + "?)"
+	}
+}
+
+// editDistance is the optimal string alignment distance between a and b,
+// i.e. the Levenshtein distance which also counts swapping two adjacent
+// characters as a single edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	dist := make([][]int, len(ra)+1)
+	for i := range dist {
+		dist[i] = make([]int, len(rb)+1)
+		dist[i][0] = i
+	}
+	for j := range dist[0] {
+		dist[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			dist[i][j] = min3(dist[i-1][j]+1, dist[i][j-1]+1, dist[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && dist[i-2][j-2]+1 < dist[i][j] {
+				dist[i][j] = dist[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return dist[len(ra)][len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
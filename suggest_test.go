@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSuggestDevs(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"ak": &dev{Name: "Akshat Shah", Email: "akshat@beef.com"},
+			"km": &dev{Name: "Karan Misra", Email: "kidoman@beef.com"},
+			"an": &dev{Name: "Anand Shankar", Email: "anand@beef.com", Handle: "anandshankar"},
+		},
+	}
+
+	tests := []struct {
+		id   string
+		ids  []string
+		hint string
+	}{
+		{
+			id:   "ka",
+			ids:  []string{"ak", "km"},
+			hint: " (did you mean ak or km?)",
+		},
+		{
+			id:   "kidomn",
+			ids:  []string{"km"},
+			hint: " (did you mean km?)",
+		},
+		{
+			id:   "Akshat",
+			ids:  []string{"ak"},
+			hint: " (did you mean ak?)",
+		},
+		{
+			id:   "+shankar",
+			ids:  []string{"an"},
+			hint: " (did you mean an?)",
+		},
+		{
+			id:   "@anandshankr",
+			ids:  []string{"an"},
+			hint: " (did you mean an?)",
+		},
+		{
+			id:   "mk",
+			ids:  []string{"ak", "km"},
+			hint: " (did you mean ak or km?)",
+		},
+		{
+			id:   "shobhit",
+			ids:  []string{},
+			hint: "",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.ids, d.suggestDevs(tt.id), "id %s", tt.id)
+		assert.Equal(t, tt.hint, d.didYouMean(tt.id), "id %s", tt.id)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		dist int
+	}{
+		{"", "", 0},
+		{"ak", "", 2},
+		{"ak", "ak", 0},
+		{"ka", "ak", 1},
+		{"ca", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"anand", "aknad", 2},
+		{"akshat", "akshta", 1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.dist, editDistance(tt.a, tt.b), "%s -> %s", tt.a, tt.b)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// teamPrefix marks a reference to a team (rather than a single dev) in
+// repo devs and the first line.
+const teamPrefix = "@"
+
+func (d *data) addTeam(name string, devRefs []string) error {
+	name = strings.TrimPrefix(name, teamPrefix)
+	if name == "" {
+		return errors.New("invalid team name")
+	}
+	if id, _ := d.lookupDevByHandle(name); id != "" {
+		return errors.Errorf("team name %s clashes with the handle of dev %s", name, id)
+	}
+
+	var members []string
+	seen := make(map[string]bool)
+	for _, ref := range devRefs {
+		id := d.resolveDevRef(ref)
+		if id == "" {
+			return errors.Errorf("no dev with id %s found%s", ref, d.didYouMean(ref))
+		}
+		if d.Devs[id].Retired {
+			return retiredError(ref)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		members = append(members, id)
+	}
+
+	if d.Teams == nil {
+		d.Teams = make(map[string][]string)
+	}
+	d.Teams[name] = members
+
+	return nil
+}
+
+func (d *data) removeTeam(name string) error {
+	name = strings.TrimPrefix(name, teamPrefix)
+	if _, ok := d.Teams[name]; !ok {
+		return errors.Errorf("no team with name %s found", name)
+	}
+
+	for path, repo := range d.Repos {
+		for _, id := range repo.Devs {
+			if id == teamPrefix+name {
+				return errors.Errorf("team %s is used by repo %s", name, path)
+			}
+		}
+	}
+
+	delete(d.Teams, name)
+
+	return nil
+}
+
+// lookupTeam returns the members of the team referenced as @name, and
+// false if ref is not a team reference.
+func (d *data) lookupTeam(ref string) ([]string, bool) {
+	if !strings.HasPrefix(ref, teamPrefix) || d.Teams == nil {
+		return nil, false
+	}
+	members, ok := d.Teams[ref[len(teamPrefix):]]
+	return members, ok
+}
+
+// expandDevIDs resolves team references, aliases and @handles to dev ids.
+// Retired devs are left out, while unknown ids are kept as is for the
+// callers to report.
+func (d *data) expandDevIDs(refs []string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+
+	add := func(id string) {
+		if seen[id] {
+			return
+		}
+		if dev := d.Devs[id]; dev != nil && dev.Retired {
+			return
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	for _, ref := range refs {
+		if members, ok := d.lookupTeam(ref); ok {
+			for _, id := range members {
+				add(id)
+			}
+			continue
+		}
+
+		if id := d.resolveDevRef(ref); id != "" {
+			add(id)
+			continue
+		}
+
+		add(ref)
+	}
+
+	return ids
+}
+
+// removeDev deletes the dev, along with their membership of teams and
+// repos.
+func (d *data) removeDev(id string) error {
+	canonicalID := d.canonicalDevID(id)
+	if canonicalID == "" {
+		return errors.Errorf("no dev with id %s found%s", id, d.didYouMean(id))
+	}
+
+	// Repos might reference the dev through one of their aliases, or their
+	// @handle (unless a team goes by the same name).
+	dev := d.Devs[canonicalID]
+	refs := append([]string{canonicalID}, dev.Aliases...)
+	if dev.Handle != "" && d.Teams[dev.Handle] == nil {
+		refs = append(refs, teamPrefix+dev.Handle)
+	}
+
+	delete(d.Devs, canonicalID)
+
+	for _, repo := range d.Repos {
+		if repo.Mob != nil {
+			repo.Mob.removeDev(canonicalID)
+		}
+	}
+
+	for _, ref := range refs {
+		for name, members := range d.Teams {
+			d.Teams[name] = without(members, ref)
+		}
+		for _, repo := range d.Repos {
+			repo.Devs = without(repo.Devs, ref)
+			repo.Navigators = without(repo.Navigators, ref)
+			if repo.Driver == ref {
+				repo.Driver = ""
+			}
+		}
+	}
+
+	return nil
+}
+
+func without(ids []string, id string) []string {
+	var result []string
+	for _, i := range ids {
+		if i != id {
+			result = append(result, i)
+		}
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTeamTestData() *data {
+	return &data{
+		Devs: map[string]*dev{
+			"ak": &dev{Name: "akshat", Email: "akshat@beef.com"},
+			"km": &dev{Name: "Karan Misra", Email: "karan@beef.com", Handle: "kidoman"},
+			"an": &dev{Name: "Anand Shankar", Email: "anand@beef.com"},
+		},
+		Repos: map[string]*repo{
+			"/a": &repo{Devs: []string{"@payments", "an"}},
+		},
+		Teams: map[string][]string{
+			"payments": {"ak", "km"},
+		},
+	}
+}
+
+func TestDataAddTeam(t *testing.T) {
+	tests := []struct {
+		name    string
+		devRefs []string
+		members []string
+		errMsg  string
+	}{
+		{
+			name:    "search",
+			devRefs: []string{"ak", "@kidoman", "ak"},
+			members: []string{"ak", "km"},
+		},
+		{
+			name:    "@payments",
+			devRefs: []string{"an"},
+			members: []string{"an"},
+		},
+		{
+			name:    "search",
+			devRefs: []string{"ak", "kn"},
+			errMsg:  "no dev with id kn found (did you mean an or km?)",
+		},
+		{
+			name:    "kidoman",
+			devRefs: []string{"ak"},
+			errMsg:  "team name kidoman clashes with the handle of dev km",
+		},
+		{
+			name:   "@",
+			errMsg: "invalid team name",
+		},
+	}
+
+	for _, tt := range tests {
+		d := newTeamTestData()
+
+		err := d.addTeam(tt.name, tt.devRefs)
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.members, d.Teams[strings.TrimPrefix(tt.name, "@")])
+		}
+	}
+}
+
+func TestDataRemoveTeam(t *testing.T) {
+	d := newTeamTestData()
+	d.Teams["search"] = []string{"an"}
+
+	assert.NoError(t, d.removeTeam("@search"))
+	assert.NotContains(t, d.Teams, "search")
+
+	err := d.removeTeam("payments")
+	if assert.Error(t, err) {
+		assert.Equal(t, "team payments is used by repo /a", err.Error())
+	}
+
+	err = d.removeTeam("search")
+	if assert.Error(t, err) {
+		assert.Equal(t, "no team with name search found", err.Error())
+	}
+}
+
+func TestDataExpandDevIDs(t *testing.T) {
+	d := newTeamTestData()
+
+	assert.Equal(t, []string{"ak", "km", "an"}, d.expandDevIDs([]string{"@payments", "an"}))
+	assert.Equal(t, []string{"km", "ak"}, d.expandDevIDs([]string{"@kidoman", "@payments"}))
+	assert.Equal(t, []string{"unknown"}, d.expandDevIDs([]string{"unknown"}))
+	assert.Equal(t, []string(nil), d.expandDevIDs(nil))
+}
+
+func TestDataValidateDevsWithTeams(t *testing.T) {
+	d := newTeamTestData()
+
+	assert.NoError(t, d.validateDevs([]string{"@payments", "an", "@kidoman"}))
+
+	err := d.validateDevs([]string{"@search"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "no team or dev with handle search found", err.Error())
+	}
+}
+
+func TestDataRemoveDev(t *testing.T) {
+	d := newTeamTestData()
+	d.Repos["/b"] = &repo{Devs: []string{"km", "an"}}
+	d.Repos["/c"] = &repo{
+		Devs: []string{"ak", "@kidoman"},
+		Mob:  &mob{Devs: []string{"ak", "km", "an"}, Driver: 2},
+	}
+
+	assert.NoError(t, d.removeDev("km"))
+	assert.NotContains(t, d.Devs, "km")
+	assert.Equal(t, []string{"ak"}, d.Teams["payments"])
+	assert.Equal(t, []string{"@payments", "an"}, d.Repos["/a"].Devs)
+	assert.Equal(t, []string{"an"}, d.Repos["/b"].Devs)
+	assert.Equal(t, []string{"ak"}, d.Repos["/c"].Devs)
+	assert.NoError(t, d.validateDevs(d.Repos["/c"].Devs))
+	assert.Equal(t, []string{"ak", "an"}, d.Repos["/c"].Mob.Devs)
+	assert.Equal(t, "an", d.Repos["/c"].Mob.driver())
+
+	err := d.removeDev("km")
+	if assert.Error(t, err) {
+		assert.Equal(t, "no dev with id km found", err.Error())
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// tracker describes how issue ids of a particular issue tracker look.
+// Known types carry their own pattern, the "custom" type uses Pattern.
+type tracker struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+const customTrackerType = "custom"
+
+var trackerPatterns = map[string]string{
+	"jira":   `[A-Z][A-Z0-9_]+-[0-9]+`,
+	"github": `#?[0-9]+`,
+	"linear": `[A-Z][A-Z0-9]*-[0-9]+`,
+}
+
+// defaultTrackers are used for repos which have not configured any.
+var defaultTrackers = []*tracker{
+	{Type: "jira"},
+	{Type: "github"},
+}
+
+// parseTracker parses a tracker spec as given on the command line. It is
+// either the name of a known tracker type or custom=<regex>.
+func parseTracker(spec string) (*tracker, error) {
+	if strings.HasPrefix(spec, customTrackerType+"=") {
+		t := &tracker{Type: customTrackerType, Pattern: spec[len(customTrackerType)+1:]}
+		if _, err := t.regexp(); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	if _, ok := trackerPatterns[spec]; !ok {
+		return nil, errors.Errorf("unknown tracker %s", spec)
+	}
+
+	return &tracker{Type: spec}, nil
+}
+
+func parseTrackers(specs []string) ([]*tracker, error) {
+	var trackers []*tracker
+	for _, spec := range specs {
+		t, err := parseTracker(spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid tracker %s", spec)
+		}
+		trackers = append(trackers, t)
+	}
+	return trackers, nil
+}
+
+func (t *tracker) regexp() (*regexp.Regexp, error) {
+	pattern := t.Pattern
+	if t.Type != customTrackerType {
+		var ok bool
+		pattern, ok = trackerPatterns[t.Type]
+		if !ok {
+			return nil, errors.Errorf("unknown tracker %s", t.Type)
+		}
+	}
+	if pattern == "" {
+		return nil, errors.Errorf("empty pattern for %s tracker", t.Type)
+	}
+
+	// The whole token has to look like an issue id, not just a part of it.
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, errors.Wrapf(err, "compile pattern for %s tracker failed", t.Type)
+	}
+	return re, nil
+}
+
+func (t *tracker) String() string {
+	if t.Type == customTrackerType {
+		return t.Type + "=" + t.Pattern
+	}
+	return t.Type
+}
+
+// issueMatcher decides whether a token is an issue id for any of the
+// trackers configured for a repo.
+type issueMatcher struct {
+	trackers []*tracker
+	res      []*regexp.Regexp
+}
+
+func newIssueMatcher(trackers []*tracker) (*issueMatcher, error) {
+	if len(trackers) == 0 {
+		trackers = defaultTrackers
+	}
+
+	m := &issueMatcher{trackers: trackers}
+	for _, t := range trackers {
+		re, err := t.regexp()
+		if err != nil {
+			return nil, err
+		}
+		m.res = append(m.res, re)
+	}
+
+	return m, nil
+}
+
+func (m *issueMatcher) match(id string) bool {
+	for _, re := range m.res {
+		if re.MatchString(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *issueMatcher) String() string {
+	names := make([]string, 0, len(m.trackers))
+	for _, t := range m.trackers {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTracker(t *testing.T) {
+	tests := []struct {
+		spec    string
+		tracker *tracker
+		errMsg  string
+	}{
+		{
+			spec:    "jira",
+			tracker: &tracker{Type: "jira"},
+		},
+		{
+			spec:    "custom=sc-[0-9]+",
+			tracker: &tracker{Type: "custom", Pattern: "sc-[0-9]+"},
+		},
+		{
+			spec:   "bugzilla",
+			errMsg: "unknown tracker bugzilla",
+		},
+		{
+			spec:   "custom=",
+			errMsg: "empty pattern for custom tracker",
+		},
+		{
+			spec:   "custom=(",
+			errMsg: "compile pattern for custom tracker failed: error parsing regexp: missing closing ): `^(?:()$`",
+		},
+	}
+
+	for _, tt := range tests {
+		tracker, err := parseTracker(tt.spec)
+
+		if tt.errMsg != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.errMsg, err.Error())
+			}
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.tracker, tracker)
+		}
+	}
+}
+
+func TestIssueMatcher(t *testing.T) {
+	tests := []struct {
+		trackers []*tracker
+		id       string
+		match    bool
+	}{
+		{id: "1337", match: true},
+		{id: "#1337", match: true},
+		{id: "GOJ-1337", match: true},
+		{id: "ak2", match: false},
+		{id: "goj-1337", match: false},
+		{id: "#13a37", match: false},
+		{
+			trackers: []*tracker{{Type: "github"}},
+			id:       "GOJ-1337",
+			match:    false,
+		},
+		{
+			trackers: []*tracker{{Type: "linear"}},
+			id:       "ENG2-12",
+			match:    true,
+		},
+		{
+			trackers: []*tracker{{Type: "custom", Pattern: "sc-[0-9]+"}},
+			id:       "sc-12",
+			match:    true,
+		},
+		{
+			trackers: []*tracker{{Type: "custom", Pattern: "sc-[0-9]+"}},
+			id:       "xsc-12",
+			match:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		m, err := newIssueMatcher(tt.trackers)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		assert.Equal(t, tt.match, m.match(tt.id), "id %s with trackers %s", tt.id, m)
+	}
+}
+
+func TestUpdateRepoTrackers(t *testing.T) {
+	d := data{
+		Repos: map[string]*repo{
+			"/a": new(repo),
+		},
+	}
+
+	assert.NoError(t, d.updateRepoScopeIssueID("/a", true))
+	assert.NoError(t, d.updateRepoTrackers("/a/b", []*tracker{{Type: "linear"}}))
+	assert.Equal(t, []*tracker{{Type: "linear"}}, d.Repos["/a"].Trackers)
+	assert.True(t, d.Repos["/a"].ScopeIssueID, "scope issue id is left as is")
+
+	assert.NoError(t, d.updateRepoScopeIssueID("/a", false))
+	assert.Equal(t, []*tracker{{Type: "linear"}}, d.Repos["/a"].Trackers, "trackers are left as is")
+	assert.False(t, d.Repos["/a"].ScopeIssueID)
+
+	err := d.updateRepoTrackers("/b", nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no repo with path /b found", err.Error())
+	}
+
+	err = d.updateRepoScopeIssueID("/b", true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "no repo with path /b found", err.Error())
+	}
+
+	err = d.updateRepoTrackers("/a", []*tracker{{Type: "bugzilla"}})
+	if assert.Error(t, err) {
+		assert.Equal(t, "trackers validation failed: unknown tracker bugzilla", err.Error())
+	}
+}
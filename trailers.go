@@ -0,0 +1,97 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// trailerRegexp matches the first line of a git trailer, like
+// `Change-Id: I12ab`.
+var trailerRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*:(?: |$)`)
+
+// scissorsLine marks the start of the diff in the message of git commit
+// --verbose. git drops everything from it onwards.
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+func isCommentLine(line string) bool {
+	return strings.HasPrefix(line, "#")
+}
+
+// isTrailerParagraph tells if every line of the paragraph (comments aside)
+// is a trailer, or the continuation of one.
+func isTrailerParagraph(lines []string) bool {
+	seen := false
+	for _, line := range lines {
+		switch {
+		case isCommentLine(line):
+		case trailerRegexp.MatchString(line):
+			seen = true
+		case seen && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+		default:
+			return false
+		}
+	}
+	return seen
+}
+
+// splitTrailers splits msg into its body, the trailers at its end and the
+// comments after them (along with the diff of git commit --verbose). The
+// trailers are the paragraphs at the end made only of trailer lines; there
+// can be more than one, as xp writes the issue id in a paragraph of its
+// own. The first paragraph is the subject, never trailers. Continuation
+// lines are joined to their trailer.
+func splitTrailers(msg string) (body string, trailers []string, comments string) {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+
+	end := len(lines)
+	for i, line := range lines {
+		if line == scissorsLine {
+			end = i
+			break
+		}
+	}
+	for end > 0 && (isCommentLine(lines[end-1]) || strings.TrimSpace(lines[end-1]) == "") {
+		end--
+	}
+	if end < len(lines) {
+		comments = strings.Join(lines[end:], "\n")
+		comments = strings.TrimLeft(comments, "\n") + "\n"
+		if strings.TrimSpace(comments) == "" {
+			comments = ""
+		}
+	}
+
+	subject := 0
+	for subject < end && strings.TrimSpace(lines[subject]) == "" {
+		subject++
+	}
+
+	// Walk back over the paragraphs which are all trailers.
+	start := end
+	for start > subject {
+		from := start
+		for from > 0 && strings.TrimSpace(lines[from-1]) != "" {
+			from--
+		}
+		if from <= subject || !isTrailerParagraph(lines[from:start]) {
+			break
+		}
+
+		start = from
+		for start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+			start--
+		}
+	}
+
+	for _, line := range lines[start:end] {
+		switch {
+		case strings.TrimSpace(line) == "" || isCommentLine(line):
+		case !trailerRegexp.MatchString(line) && len(trailers) != 0:
+			trailers[len(trailers)-1] += "\n" + line
+		default:
+			trailers = append(trailers, line)
+		}
+	}
+
+	return strings.Join(lines[:start], "\n"), trailers, comments
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTrailers(t *testing.T) {
+	tests := []struct {
+		msg      string
+		body     string
+		trailers []string
+		comments string
+	}{
+		{
+			msg:  "Line 1",
+			body: "Line 1",
+		},
+		{
+			// The subject is never a trailer.
+			msg:  "Fix: the thing\n",
+			body: "Fix: the thing",
+		},
+		{
+			msg:      "Line 1\n\nLine 2\n\nIssue-id: GOJ-1\n\nCo-authored-by: A <a@b.com>\nChange-Id: I12ab\n",
+			body:     "Line 1\n\nLine 2",
+			trailers: []string{"Issue-id: GOJ-1", "Co-authored-by: A <a@b.com>", "Change-Id: I12ab"},
+		},
+		{
+			// Only the paragraphs at the end are trailers.
+			msg:      "Line 1\n\nSigned-off-by: A <a@b.com>\n\nLine 3\n\nCc: B <b@c.com>",
+			body:     "Line 1\n\nSigned-off-by: A <a@b.com>\n\nLine 3",
+			trailers: []string{"Cc: B <b@c.com>"},
+		},
+		{
+			msg:      "Line 1\n\nLine 2\nCo-authored-by: A <a@b.com>",
+			body:     "Line 1\n\nLine 2\nCo-authored-by: A <a@b.com>",
+			trailers: nil,
+		},
+		{
+			msg:      "Line 1\n\nCc: A\n  <a@b.com>\n\n# Please enter the commit message\n\n" + scissorsLine + "\ndiff\n",
+			body:     "Line 1",
+			trailers: []string{"Cc: A\n  <a@b.com>"},
+			comments: "# Please enter the commit message\n\n" + scissorsLine + "\ndiff\n",
+		},
+		{
+			msg:      "\n# Please enter the commit message\n",
+			body:     "",
+			comments: "# Please enter the commit message\n",
+		},
+	}
+
+	for _, tt := range tests {
+		body, trailers, comments := splitTrailers(tt.msg)
+		assert.Equal(t, tt.body, body, "msg %q", tt.msg)
+		assert.Equal(t, tt.trailers, trailers, "msg %q", tt.msg)
+		assert.Equal(t, tt.comments, comments, "msg %q", tt.msg)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Periods the trend can be reported by.
+const (
+	periodWeek  = "week"
+	periodMonth = "month"
+)
+
+var trendPeriods = []string{periodWeek, periodMonth}
+
+const formatCSV = "csv"
+
+var trendFormats = []string{formatText, formatCSV}
+
+// sparks are the levels of a sparkline, from none to all commits paired.
+// noSpark marks a period without commits.
+const (
+	sparks  = "▁▂▃▄▅▆▇█"
+	noSpark = "·"
+)
+
+// periodStart is the start of the week (on Monday) or month t falls in.
+func periodStart(t time.Time, by string) time.Time {
+	y, m, day := t.Date()
+	if by == periodMonth {
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(y, m, day-offset, 0, 0, 0, 0, t.Location())
+}
+
+func nextPeriod(t time.Time, by string) time.Time {
+	if by == periodMonth {
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 7)
+}
+
+func checkPeriod(by string) error {
+	if !contains(trendPeriods, by) {
+		return errors.Errorf("unknown period %s, expected one of %s", by, strings.Join(trendPeriods, ", "))
+	}
+	return nil
+}
+
+func periodLabel(t time.Time, by string) string {
+	if by == periodMonth {
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
+
+// trendRow counts the commits of a dev or team in each period, and how
+// many of them were paired on.
+type trendRow struct {
+	name    string
+	commits map[time.Time]int
+	paired  map[time.Time]int
+}
+
+func newTrendRow(name string) *trendRow {
+	return &trendRow{
+		name:    name,
+		commits: make(map[time.Time]int),
+		paired:  make(map[time.Time]int),
+	}
+}
+
+func (r *trendRow) add(period time.Time, paired bool) {
+	r.commits[period]++
+	if paired {
+		r.paired[period]++
+	}
+}
+
+func (r *trendRow) total() (commits, paired int) {
+	for p, n := range r.commits {
+		commits += n
+		paired += r.paired[p]
+	}
+	return commits, paired
+}
+
+// trend is the share of paired commits over time, for each dev and team.
+type trend struct {
+	by      string
+	periods []time.Time
+	rows    []*trendRow
+}
+
+// pairedCommit tells if c has a co-author from the roster, other than its
+// author.
+func (d *data) pairedCommit(c *commit) bool {
+	author := d.contributorID(c.author.Email)
+	for _, dev := range c.coAuthors {
+		id, _ := d.lookupDevByEmail(dev.Email)
+		if id != "" && id != author {
+			return true
+		}
+	}
+	return false
+}
+
+// maxTrendPeriods caps how wide the trend gets. Weeks spanning more than
+// that are reported by month instead, and only the latest months are kept.
+const maxTrendPeriods = 52
+
+// trendPeriodsBetween are the periods from first to last, by week or month. by is
+// switched to month past maxTrendPeriods weeks.
+func trendPeriodsBetween(first, last time.Time, by string) ([]time.Time, string) {
+	var periods []time.Time
+	for p := periodStart(first, by); !p.After(last); p = nextPeriod(p, by) {
+		periods = append(periods, p)
+	}
+
+	if len(periods) > maxTrendPeriods && by == periodWeek {
+		return trendPeriodsBetween(first, last, periodMonth)
+	}
+	if len(periods) > maxTrendPeriods {
+		periods = periods[len(periods)-maxTrendPeriods:]
+	}
+	return periods, by
+}
+
+// trend counts the commits authored by each roster dev, and by the members
+// of each team, in every period from the first commit to the last.
+func (d *data) trend(commits []*commit, by string) *trend {
+	t := &trend{by: by}
+	if len(commits) == 0 {
+		return t
+	}
+
+	first, last := commits[0].date, commits[0].date
+	for _, c := range commits {
+		if c.date.Before(first) {
+			first = c.date
+		}
+		if c.date.After(last) {
+			last = c.date
+		}
+	}
+	t.periods, t.by = trendPeriodsBetween(first, last, by)
+
+	devRows := make(map[string]*trendRow)
+	teamRows := make(map[string]*trendRow)
+
+	for _, c := range commits {
+		period := periodStart(c.date, t.by)
+		if period.Before(t.periods[0]) {
+			continue
+		}
+
+		id, _ := d.lookupDevByEmail(c.author.Email)
+		if id == "" {
+			continue
+		}
+
+		paired := d.pairedCommit(c)
+		if devRows[id] == nil {
+			devRows[id] = newTrendRow(id)
+		}
+		devRows[id].add(period, paired)
+
+		for name, members := range d.Teams {
+			if !contains(members, id) {
+				continue
+			}
+			if teamRows[name] == nil {
+				teamRows[name] = newTrendRow(teamPrefix + name)
+			}
+			teamRows[name].add(period, paired)
+		}
+	}
+
+	for _, rows := range []map[string]*trendRow{devRows, teamRows} {
+		names := make([]string, 0, len(rows))
+		for name := range rows {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			t.rows = append(t.rows, rows[name])
+		}
+	}
+	return t
+}
+
+// sparkLevel is the level of the spark for paired commits out of n, from 0
+// for none to 7 for all of them.
+func sparkLevel(paired, n int) int {
+	top := len([]rune(sparks)) - 1
+	return (paired*top + n/2) / n
+}
+
+func sparkline(r *trendRow, periods []time.Time) string {
+	levels := []rune(sparks)
+
+	var b strings.Builder
+	for _, p := range periods {
+		n := r.commits[p]
+		if n == 0 {
+			b.WriteString(noSpark)
+			continue
+		}
+		b.WriteRune(levels[sparkLevel(r.paired[p], n)])
+	}
+	return b.String()
+}
+
+func share(paired, commits int) int {
+	if commits == 0 {
+		return 0
+	}
+	return (paired*100 + commits/2) / commits
+}
+
+func writeTrend(w io.Writer, t *trend, format string) error {
+	if err := checkFormat(format, trendFormats); err != nil {
+		return err
+	}
+
+	if format == formatCSV {
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"period", "name", "commits", "paired", "share"})
+		for _, p := range t.periods {
+			for _, r := range t.rows {
+				n := r.commits[p]
+				if n == 0 {
+					continue
+				}
+				cw.Write([]string{
+					periodLabel(p, t.by),
+					r.name,
+					strconv.Itoa(n),
+					strconv.Itoa(r.paired[p]),
+					strconv.FormatFloat(float64(r.paired[p])/float64(n), 'f', 2, 64),
+				})
+			}
+		}
+		cw.Flush()
+		return errors.Wrap(cw.Error(), "write csv failed")
+	}
+
+	if len(t.rows) == 0 {
+		fmt.Fprintln(w, "no commits by devs in the roster")
+		return nil
+	}
+
+	fmt.Fprintf(w, "paired commits by %s, from %s to %s\n", t.by,
+		periodLabel(t.periods[0], t.by), periodLabel(t.periods[len(t.periods)-1], t.by))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTREND\tPAIRED")
+	for _, r := range t.rows {
+		commits, paired := r.total()
+		fmt.Fprintf(tw, "%s\t%s\t%d%% (%d/%d)\n", r.name, sparkline(r, t.periods), share(paired, commits), paired, commits)
+	}
+	return tw.Flush()
+}
+
+// defaultTrendSince bounds the history the trend looks at by default.
+const defaultTrendSince = "6m"
+
+// repoTrend writes the trend of paired commits of the current repo, or of
+// every repo with allRepos, by week or month.
+func (d *data) repoTrend(w io.Writer, since string, allRepos bool, workers int, by, format string) error {
+	if err := checkPeriod(by); err != nil {
+		return err
+	}
+	if err := checkFormat(format, trendFormats); err != nil {
+		return err
+	}
+
+	commits, err := d.statsHistory(since, allRepos, workers)
+	if err != nil {
+		return err
+	}
+
+	return writeTrend(w, d.trend(commits, by), format)
+}
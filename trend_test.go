@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// 2019-03-07 is a Thursday.
+	thu := time.Date(2019, 3, 7, 15, 4, 5, 0, time.UTC)
+	sun := time.Date(2019, 3, 10, 23, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC), periodStart(thu, periodWeek))
+	assert.Equal(t, time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC), periodStart(sun, periodWeek))
+	assert.Equal(t, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), periodStart(thu, periodMonth))
+}
+
+func trendData() *data {
+	return &data{
+		Devs: map[string]*dev{
+			"karan":  {Name: "Karan Misra", Email: "karan@beef.com"},
+			"anand":  {Name: "Anand Shankar", Email: "anand@beef.com"},
+			"akshat": {Name: "Akshat Shah", Email: "akshat@beef.com"},
+		},
+		Teams: map[string][]string{
+			"pay": {"karan", "anand"},
+		},
+	}
+}
+
+func trendCommits() []*commit {
+	var (
+		karan  = &dev{Name: "Karan Misra", Email: "karan@beef.com"}
+		anand  = &dev{Name: "Anand Shankar", Email: "anand@beef.com"}
+		akshat = &dev{Name: "Akshat Shah", Email: "akshat@beef.com"}
+		ext    = &dev{Name: "Ext", Email: "ext@corp.com"}
+	)
+	day := func(d int) time.Time {
+		return time.Date(2019, 3, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	return []*commit{
+		// Week of 03-04.
+		{author: karan, date: day(4), coAuthors: []*dev{anand}},
+		{author: karan, date: day(5), coAuthors: []*dev{ext}},
+		{author: anand, date: day(6), coAuthors: []*dev{karan}},
+		// Nothing in the week of 03-11.
+		// Week of 03-18.
+		{author: karan, date: day(18), coAuthors: []*dev{karan}},
+		{author: akshat, date: day(19), coAuthors: []*dev{karan}},
+		{author: ext, date: day(20), coAuthors: []*dev{karan}},
+		// Week of 03-25.
+		{author: anand, date: day(25), coAuthors: []*dev{akshat}},
+		{author: karan, date: day(26), coAuthors: []*dev{akshat}},
+	}
+}
+
+func TestTrend(t *testing.T) {
+	tr := trendData().trend(trendCommits(), periodWeek)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(2019, 3, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2019, 3, 18, 0, 0, 0, 0, time.UTC),
+		time.Date(2019, 3, 25, 0, 0, 0, 0, time.UTC),
+	}, tr.periods)
+
+	var names []string
+	for _, r := range tr.rows {
+		names = append(names, r.name)
+	}
+	assert.Equal(t, []string{"akshat", "anand", "karan", "@pay"}, names)
+
+	karan := tr.rows[2]
+	commits, paired := karan.total()
+	assert.Equal(t, 4, commits)
+	assert.Equal(t, 2, paired)
+	assert.Equal(t, "▅·▁█", sparkline(karan, tr.periods))
+
+	assert.Empty(t, trendData().trend(nil, periodWeek).rows)
+}
+
+func TestTrendPeriodsBetween(t *testing.T) {
+	first := time.Date(2019, 3, 6, 0, 0, 0, 0, time.UTC)
+
+	periods, by := trendPeriodsBetween(first, first.AddDate(0, 6, 0), periodWeek)
+	assert.Equal(t, periodWeek, by)
+	assert.Len(t, periods, 27)
+
+	periods, by = trendPeriodsBetween(first, first.AddDate(2, 0, 0), periodWeek)
+	assert.Equal(t, periodMonth, by)
+	assert.Len(t, periods, 25)
+	assert.Equal(t, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), periods[0])
+
+	periods, by = trendPeriodsBetween(first, first.AddDate(10, 0, 0), periodWeek)
+	assert.Equal(t, periodMonth, by)
+	assert.Len(t, periods, maxTrendPeriods)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), periods[0])
+}
+
+func TestTrendCapped(t *testing.T) {
+	d := trendData()
+	karan := d.Devs["karan"]
+
+	commits := []*commit{
+		{author: karan, date: time.Date(2009, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{author: karan, date: time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tr := d.trend(commits, periodWeek)
+	assert.Equal(t, periodMonth, tr.by)
+	assert.Len(t, tr.periods, maxTrendPeriods)
+
+	// The commit from before the periods kept is left out.
+	commitsIn, _ := tr.rows[0].total()
+	assert.Equal(t, 1, commitsIn)
+}
+
+func TestWriteTrend(t *testing.T) {
+	tests := []struct {
+		by     string
+		format string
+		output string
+	}{
+		{
+			by:     periodWeek,
+			format: formatText,
+			output: "" +
+				"paired commits by week, from 2019-03-04 to 2019-03-25\n" +
+				"NAME    TREND  PAIRED\n" +
+				"akshat  ··█·   100% (1/1)\n" +
+				"anand   █··█   100% (2/2)\n" +
+				"karan   ▅·▁█   50% (2/4)\n" +
+				"@pay    ▆·▁█   67% (4/6)\n",
+		},
+		{
+			by:     periodMonth,
+			format: formatText,
+			output: "" +
+				"paired commits by month, from 2019-03 to 2019-03\n" +
+				"NAME    TREND  PAIRED\n" +
+				"akshat  █      100% (1/1)\n" +
+				"anand   █      100% (2/2)\n" +
+				"karan   ▅      50% (2/4)\n" +
+				"@pay    ▆      67% (4/6)\n",
+		},
+		{
+			by:     periodWeek,
+			format: formatCSV,
+			output: "" +
+				"period,name,commits,paired,share\n" +
+				"2019-03-04,anand,1,1,1.00\n" +
+				"2019-03-04,karan,2,1,0.50\n" +
+				"2019-03-04,@pay,3,2,0.67\n" +
+				"2019-03-18,akshat,1,1,1.00\n" +
+				"2019-03-18,karan,1,0,0.00\n" +
+				"2019-03-18,@pay,1,0,0.00\n" +
+				"2019-03-25,anand,1,1,1.00\n" +
+				"2019-03-25,karan,1,1,1.00\n" +
+				"2019-03-25,@pay,2,2,1.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		err := writeTrend(&buf, trendData().trend(trendCommits(), tt.by), tt.format)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.output, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeTrend(&buf, &trend{by: periodWeek}, formatText))
+	assert.Equal(t, "no commits by devs in the roster\n", buf.String())
+}
+
+func TestRepoTrend(t *testing.T) {
+	d := trendData()
+	d.StatsFilters = &statsFilters{IncludeMerges: true}
+
+	output := logRecord("b2", "dependabot[bot] <bot@github.com>", 1551800000, "Bump deps\n") +
+		logRecord("a1", "Karan Misra <karan@beef.com>", 1551700000,
+			"Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n")
+
+	calls, restore := stubGit(map[string]string{
+		"log":           output,
+		"check-mailmap": "Anand Shankar <anand@beef.com>",
+	}, "")
+	defer restore()
+
+	var buf bytes.Buffer
+	err := d.repoTrend(&buf, "", false, 1, periodWeek, formatCSV)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "log "+historyFormat, (*calls)[0])
+		assert.Equal(t, "period,name,commits,paired,share\n"+
+			"2019-03-04,karan,1,1,1.00\n"+
+			"2019-03-04,@pay,1,1,1.00\n", buf.String())
+	}
+
+	*calls = nil
+	buf.Reset()
+	err = d.repoTrend(&buf, allHistory, false, 1, periodWeek, formatCSV)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "log "+historyFormat, (*calls)[0])
+	}
+
+	err = d.repoTrend(&buf, "", false, 1, "day", formatText)
+	if assert.Error(t, err) {
+		assert.Equal(t, "unknown period day, expected one of week, month", err.Error())
+	}
+
+	err = d.repoTrend(&buf, "", false, 1, periodWeek, formatJSON)
+	if assert.Error(t, err) {
+		assert.Equal(t, "unknown format json, expected one of text, csv", err.Error())
+	}
+}
@@ -9,8 +9,6 @@ import (
 	"os"
 	"os/exec"
 	"path"
-	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 
@@ -19,8 +17,11 @@ import (
 )
 
 type data struct {
-	Devs  map[string]*dev  `json:"devs"`
-	Repos map[string]*repo `json:"repos"`
+	Devs  map[string]*dev     `json:"devs"`
+	Repos map[string]*repo    `json:"repos"`
+	Teams map[string][]string `json:"teams,omitempty"`
+
+	StatsFilters *statsFilters `json:"statsFilters,omitempty"`
 }
 
 func load(r io.Reader) (*data, error) {
@@ -57,36 +58,163 @@ func (d *data) store(w io.Writer) error {
 type dev struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
+
+	// Handle and ForgeID identify the dev on GitHub, and are used to
+	// credit them with their noreply email.
+	Handle  string `json:"handle,omitempty"`
+	ForgeID int    `json:"forgeId,omitempty"`
+
+	// Aliases are other ids the dev can be referenced by.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Retired devs are kept to recognize them in history, but can no
+	// longer be credited in new commits.
+	Retired bool `json:"retired,omitempty"`
+
+	// SignOff opts the dev in to a Signed-off-by trailer when they are
+	// a co-author in repos requiring sign-offs.
+	SignOff bool `json:"signOff,omitempty"`
+
+	// Emails are other emails the dev commits with. They are recognized
+	// in history and as the author, but never credited.
+	Emails []string `json:"emails,omitempty"`
 }
 
 func (d *dev) String() string {
 	return d.Name + " <" + d.Email + ">"
 }
 
-func (d *data) addDev(id, name, email string) {
+// hasEmail tells if the dev is known by email: their email, one of their
+// other emails or their GitHub noreply email.
+func (d *dev) hasEmail(email string) bool {
+	if sameEmail(d.Email, email) || (d.Handle != "" && sameEmail(d.noreplyEmail(), email)) {
+		return true
+	}
+	for _, e := range d.Emails {
+		if sameEmail(e, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// addDev adds the dev, or updates the name and email of an existing one
+// (by id or alias) while keeping the rest of their settings. The previous
+// email is kept as one of their other emails, so their history is still
+// recognized. An email can only belong to one dev, retired or not.
+func (d *data) addDev(id, name, email string) error {
 	if d.Devs == nil {
 		d.Devs = make(map[string]*dev)
 	}
-	d.Devs[id] = &dev{Name: name, Email: email}
+
+	canonicalID := d.canonicalDevID(id)
+	if otherID, _ := d.lookupDevByEmail(email); otherID != "" && otherID != canonicalID {
+		return errors.Errorf("email %s already belongs to dev %s", email, otherID)
+	}
+
+	existing := d.Devs[canonicalID]
+	if existing == nil {
+		d.Devs[id] = &dev{Name: name, Email: email}
+		return nil
+	}
+
+	if !sameEmail(existing.Email, email) {
+		existing.Emails = append(existing.Emails, existing.Email)
+	}
+	existing.Emails = withoutEmail(existing.Emails, email)
+	existing.Name, existing.Email = name, email
+
+	return nil
+}
+
+func withoutEmail(emails []string, email string) []string {
+	var result []string
+	for _, e := range emails {
+		if !sameEmail(e, email) {
+			result = append(result, e)
+		}
+	}
+	return result
 }
 
 func (d *data) lookupDev(id string) *dev {
 	if d.Devs == nil {
 		return nil
 	}
-	return d.Devs[id]
+	if dev := d.Devs[id]; dev != nil {
+		return dev
+	}
+	return d.Devs[d.canonicalDevID(id)]
+}
+
+// lookupDevByEmail finds the dev known by the given email, see hasEmail.
+func (d *data) lookupDevByEmail(email string) (string, *dev) {
+	for id, dev := range d.Devs {
+		if dev.hasEmail(email) {
+			return id, dev
+		}
+	}
+	return "", nil
+}
+
+// resolveDevRef returns the id of the dev referenced either by their id,
+// one of their aliases or by their @handle. An empty string is returned
+// if there is no such dev.
+func (d *data) resolveDevRef(ref string) string {
+	if strings.HasPrefix(ref, "@") {
+		id, _ := d.lookupDevByHandle(ref[1:])
+		return id
+	}
+
+	return d.canonicalDevID(ref)
 }
 
 type repo struct {
-	Devs    []string `json:"devs"`
-	IssueID string   `json:"issueId"`
+	Devs     []string   `json:"devs"`
+	IssueID  string     `json:"issueId"`
+	Trackers []*tracker `json:"trackers,omitempty"`
+	Policy   *policy    `json:"policy,omitempty"`
+
+	// NoreplyEmails credits devs with a handle using their GitHub noreply
+	// email instead of their email.
+	NoreplyEmails bool `json:"noreplyEmails,omitempty"`
+
+	Mob *mob `json:"mob,omitempty"`
+
+	// Driver and Navigators are the roles of the repo devs, recorded
+	// using RoleTrailers.
+	Driver       string        `json:"driver,omitempty"`
+	Navigators   []string      `json:"navigators,omitempty"`
+	RoleTrailers *roleTrailers `json:"roleTrailers,omitempty"`
+
+	// ScopeIssueID takes the issue id from the scope of a Conventional
+	// Commits header, like in `feat(GOJ-1337): ...`.
+	ScopeIssueID bool `json:"scopeIssueId,omitempty"`
+
+	// CoAuthorOrder is the order co-authors are written in, by email
+	// when not set.
+	CoAuthorOrder string `json:"coAuthorOrder,omitempty"`
+
+	// SignOff adds a Signed-off-by trailer (DCO) for the author, and the
+	// co-authors who opted in.
+	SignOff bool `json:"signOff,omitempty"`
 }
 
 func (d *data) validateDevs(devIDs []string) error {
 	for _, did := range devIDs {
-		if d.lookupDev(did) == nil {
-			return errors.Errorf("no dev with id %s found", did)
+		if _, ok := d.lookupTeam(did); ok {
+			continue
 		}
+		if id := d.resolveDevRef(did); id != "" {
+			if d.Devs[id].Retired {
+				return retiredError(did)
+			}
+			continue
+		}
+		if strings.HasPrefix(did, teamPrefix) {
+			return errors.Errorf("no team or dev with handle %s found", did[len(teamPrefix):])
+		}
+		return errors.Errorf("no dev with id %s found%s", did, d.didYouMean(did))
 	}
 	return nil
 }
@@ -100,6 +228,18 @@ func (d *data) addRepo(path string, devIDs []string, issueID string) error {
 		return errors.Wrap(err, "dev ids validation failed")
 	}
 
+	// Re-adding a repo keeps its other settings, and its devs and issue id
+	// unless new ones are given.
+	if repo := d.Repos[path]; repo != nil {
+		if devIDs != nil {
+			repo.Devs = devIDs
+		}
+		if issueID != "" {
+			repo.IssueID = issueID
+		}
+		return nil
+	}
+
 	d.Repos[path] = &repo{
 		Devs:    devIDs,
 		IssueID: issueID,
@@ -116,18 +256,17 @@ func initRepo(pathStr string, overwrite bool, xpBinPath string) error {
 	}
 
 	if !overwrite {
-		for _, hookFile := range hookFiles {
-			if _, err := os.Stat(path.Join(gitPath, hookFile)); err == nil {
+		for _, hook := range hooks {
+			if _, err := os.Stat(path.Join(gitPath, hook.file)); err == nil {
 				// TODO: Check if it is our prepare-commit-msg hook.
-				return errors.Errorf("%s is already defined", hookFile)
+				return errors.Errorf("%s is already defined", hook.file)
 			}
 		}
 	}
 
-	hookStr := fmt.Sprintf(hookStrTmpl, xpBinPath)
-
-	for _, hookFile := range hookFiles {
-		hookFile = path.Join(gitPath, hookFile)
+	for _, hook := range hooks {
+		hookFile := path.Join(gitPath, hook.file)
+		hookStr := fmt.Sprintf(hookStrTmpl, xpBinPath, hook.cmd)
 
 		f, err := os.OpenFile(hookFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
 		if err != nil {
@@ -146,13 +285,17 @@ func initRepo(pathStr string, overwrite bool, xpBinPath string) error {
 	return nil
 }
 
-var hookFiles = []string{
-	"hooks/prepare-commit-msg",
-	"hooks/commit-msg",
+// hooks maps the git hooks xp installs to the xp command they run. The
+// commit-msg hook validates the message against the repo policy.
+var hooks = []struct {
+	file, cmd string
+}{
+	{"hooks/prepare-commit-msg", "add-info"},
+	{"hooks/commit-msg", "check-msg"},
 }
 
 var hookStrTmpl = `#!/bin/sh
-%s add-info $1
+%s %s $1
 `
 
 func (d *data) lookupRepo(pathStr string) (string, *repo) {
@@ -194,6 +337,32 @@ func (d *data) updateRepoDevs(wd string, devIDs []string) error {
 	return nil
 }
 
+func (d *data) updateRepoTrackers(wd string, trackers []*tracker) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	if _, err := newIssueMatcher(trackers); err != nil {
+		return errors.Wrap(err, "trackers validation failed")
+	}
+
+	repo.Trackers = trackers
+
+	return nil
+}
+
+func (d *data) updateRepoScopeIssueID(wd string, scopeIssueID bool) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	repo.ScopeIssueID = scopeIssueID
+
+	return nil
+}
+
 const issueIDPrefix = "Issue-id: "
 
 func (d *data) appendInfo(wd, msgFile string) error {
@@ -202,72 +371,270 @@ func (d *data) appendInfo(wd, msgFile string) error {
 		return errors.Errorf("no repo with path %s found", wd)
 	}
 
-	// GIT_COMMITTER_IDENT can be used to get committer info.
+	// The author reflects overrides like --author and GIT_AUTHOR_*, while
+	// the committer is whoever is actually making the commit.
 	author, err := gitVar("GIT_AUTHOR_IDENT")
 	if err != nil {
 		return errors.Wrap(err, "get author info failed")
 	}
-	authorName, authorEmail := nameEmail(author)
+	committer, err := gitVar("GIT_COMMITTER_IDENT")
+	if err != nil {
+		return errors.Wrap(err, "get committer info failed")
+	}
 
 	msg, err := ioutil.ReadFile(msgFile)
 	if err != nil {
 		return errors.Wrapf(err, "read commit msg from file %s failed", msgFile)
 	}
 
+	info, err := d.resolveInfo(repoPath, repo, author, committer, string(msg))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("total devs: %v", len(info.coAuthors)+len(info.skipped))
+	for _, a := range info.skipped {
+		log.Printf("skipping %s (same as author)", a.dev)
+	}
+	for _, a := range info.coAuthors {
+		log.Printf("added %s as author", a.dev)
+	}
+
+	f, err := os.OpenFile(msgFile, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return errors.Wrapf(err, "open on commit msg file %s failed", msgFile)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, strings.NewReader(info.String())); err != nil {
+		return errors.Wrapf(err, "write existing msg back failed")
+	}
+
+	return nil
+}
+
+// Sources of the co-authors and issue id of a commit.
+const (
+	sourceFirstLine = "first line"
+	sourceTrailer   = "existing trailer"
+	sourceRepo      = "repo default"
+	sourceMob       = "mob"
+	sourceCommitter = "committer"
+	sourceScope     = "conventional commit scope"
+)
+
+type attributedDev struct {
+	dev    *dev
+	source string
+}
+
+// commitInfo is the outcome of resolving the xp info of a commit message.
+type commitInfo struct {
+	msg           string
+	issueID       string
+	issueIDSource string
+
+	coAuthors []*attributedDev
+	// skipped are the devs which were not added since they are the
+	// author of the commit.
+	skipped []*attributedDev
+
+	roles    *pairRoles
+	roleKeys roleTrailers
+
+	signOffs []*dev
+
+	// trailers are the trailers of the message xp does not manage, and
+	// comments the git comments after them.
+	trailers []string
+	comments string
+}
+
+// String renders the commit message with the xp info appended.
+func (c *commitInfo) String() string {
+	var b strings.Builder
+
+	b.WriteString(c.msg)
+	b.WriteString("\n\n")
+
+	if c.issueID != "" {
+		if _, err := strconv.Atoi(c.issueID); err == nil {
+			fmt.Fprintf(&b, "%s#%s\n\n", issueIDPrefix, c.issueID)
+		} else {
+			fmt.Fprintf(&b, "%s%s\n\n", issueIDPrefix, c.issueID)
+		}
+	}
+
+	for _, t := range c.trailers {
+		fmt.Fprintf(&b, "%s\n", t)
+	}
+
+	for _, a := range c.coAuthors {
+		fmt.Fprintf(&b, "Co-authored-by: %s <%s>\n", a.dev.Name, a.dev.Email)
+	}
+
+	if !c.roles.empty() {
+		if c.roles.driver != nil {
+			fmt.Fprintf(&b, "%s: %s <%s>\n", c.roleKeys.Driver, c.roles.driver.Name, c.roles.driver.Email)
+		}
+		for _, dev := range c.roles.navigators {
+			fmt.Fprintf(&b, "%s: %s <%s>\n", c.roleKeys.Navigator, dev.Name, dev.Email)
+		}
+	}
+
+	for _, dev := range c.signOffs {
+		fmt.Fprintf(&b, "%s %s <%s>\n", signOffPrefix, dev.Name, dev.Email)
+	}
+
+	if c.comments != "" {
+		b.WriteString("\n")
+		b.WriteString(c.comments)
+	}
+
+	return b.String()
+}
+
+func (d *data) resolveInfo(repoPath string, repo *repo, author, committer, msgStr string) (*commitInfo, error) {
+	authorName, authorEmail := nameEmail(author)
+	_, committerEmail := nameEmail(committer)
+
+	issues, err := newIssueMatcher(repo.Trackers)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid trackers for repo %s", repoPath)
+	}
+
+	// Only the trailers at the end of the message are taken into account,
+	// and the ones xp does not manage are kept as they are.
+	msgStr, trailers, comments := splitTrailers(msgStr)
+	trailerStr := strings.Join(trailers, "\n")
+
 	var (
-		msgStr = string(msg)
+		devs    = newCoAuthorSet()
+		edevs   = existingDevs(trailerStr)
+		esigns  = existingSignOffs(trailerStr)
+		issueID = existingIssueID(trailerStr, issues)
 
-		devs    = make(map[string]*dev)
-		edevs   = existingDevs(msgStr)
-		issueID = existingIssueID(msgStr)
+		issueIDSource string
 	)
 
+	if issueID != "" {
+		issueIDSource = sourceTrailer
+	}
+
 	for _, dev := range edevs {
-		devs[dev.Email] = dev
+		devs.add(&attributedDev{dev: dev, source: sourceTrailer})
 	}
 
-	ids, endIdx := firstLineIDs(msgStr)
+	roleKeys := repo.roleTrailers()
+	roles := existingRoles(trailerStr, roleKeys)
+
+	// Set when the first line decides the devs on its own (possibly
+	// none), so the repo devs should not be used as a fallback.
+	var explicit, solo bool
+
+	// With a Conventional Commits header, the ids block is expected
+	// right after it: `feat(api): [a,b] Hello`.
+	header, rest := splitConventionalHeader(msgStr)
+
+	ids, endIdx := firstLineIDs(rest)
 	if len(ids) != 0 {
-		msgStr = msgStr[endIdx:]
+		msgStr = header + strings.TrimLeft(rest[endIdx:], " ")
 
-		devs = make(map[string]*dev)
-		for i, id := range ids {
-			dev := d.lookupDev(id)
-			if dev != nil {
-				devs[dev.Email] = dev
-				continue
-			}
+		sel, err := d.parseFirstLineIDs(ids, issues)
+		if err != nil {
+			return nil, err
+		}
+		if sel.issueID != "" {
+			issueID = sel.issueID
+			issueIDSource = sourceFirstLine
+		}
 
-			if i == 0 && issueIDRegexp.MatchString(id) {
-				// We will assume the the first id (if not a dev)
-				// is the issue id.
-				issueID = id
-				continue
+		devs = newCoAuthorSet()
+
+		devIDs := sel.devIDs
+		if len(devIDs) == 0 && sel.relative() {
+			devIDs = d.expandDevIDs(repo.sessionDevs())
+		}
+		devIDs = sel.apply(devIDs)
+
+		for _, devID := range devIDs {
+			dev := d.lookupDev(devID)
+			if dev == nil {
+				return nil, errors.Errorf("non-existing dev %s marked as working for repo %s", devID, repoPath)
 			}
-			return errors.Errorf("non-existing dev %s provided in the first line", id)
+
+			devs.add(&attributedDev{dev: dev, source: sourceFirstLine})
+		}
+
+		explicit = sel.solo || len(sel.devIDs) != 0 || sel.relative()
+		solo = sel.solo
+
+		// Like the devs, existing roles are replaced by the first line.
+		roles = nil
+		if sel.driver != "" {
+			roles = d.devRoles(sel.driver, devIDs, sourceFirstLine)
+		}
+	}
+
+	// An issue id in the ids block still takes precedence over the scope.
+	if repo.ScopeIssueID && issueIDSource != sourceFirstLine {
+		h := parseConventionalHeader(strings.TrimLeft(msgStr, " "))
+		if h != nil && issues.match(h.scope) {
+			issueID = h.scope
+			issueIDSource = sourceScope
 		}
 	}
 
+	// The issue id set for the repo (by xp pair or init --story-id) comes
+	// last, for commits which do not mention one.
+	if issueID == "" && repo.IssueID != "" {
+		issueID = repo.IssueID
+		issueIDSource = sourceRepo
+	}
+
 	// We only look at repo devs if both existing and first line devs
 	// are not specifying any devs.
-	if len(devs) == 0 {
-		for _, devID := range repo.Devs {
+	if devs.len() == 0 && !explicit {
+		source := sourceRepo
+		if repo.Mob != nil {
+			source = sourceMob
+		}
+
+		if roles == nil {
+			roles = d.repoRoles(repo)
+		}
+
+		for _, devID := range d.expandDevIDs(repo.sessionDevs()) {
 			dev := d.lookupDev(devID)
 			if dev == nil {
-				return errors.Errorf("non-existing dev %s marked as working for repo %s", devID, repoPath)
+				return nil, errors.Errorf("non-existing dev %s marked as working for repo %s", devID, repoPath)
 			}
 
-			devs[dev.Email] = dev
+			devs.add(&attributedDev{dev: dev, source: source})
 		}
 	}
 
-	issueIDIdx := strings.Index(msgStr, issueIDPrefix)
-	if issueIDIdx != -1 {
-		msgStr = msgStr[:issueIDIdx-1]
-	} else {
-		coAuthorIdx := strings.Index(msgStr, "Co-authored-by:")
-		if coAuthorIdx != -1 {
-			msgStr = msgStr[:coAuthorIdx-1]
+	// When committing on behalf of someone else (git commit --author), the
+	// person actually making the commit is credited as a co-author. Only
+	// devs in the roster are, so bots and CI committing don't get credit.
+	if !solo && !sameEmail(committerEmail, authorEmail) && !devs.has(committerEmail) {
+		if _, c := d.lookupDevByEmail(committerEmail); c != nil && !c.Retired {
+			devs.add(&attributedDev{dev: c, source: sourceCommitter})
+		}
+	}
+
+	// The xp trailers are written back below, the others are kept.
+	var otherTrailers []string
+	for _, t := range trailers {
+		managed := false
+		for _, prefix := range []string{issueIDPrefix, "Co-authored-by:", roleKeys.Driver + ":", roleKeys.Navigator + ":", signOffPrefix} {
+			if strings.HasPrefix(t, prefix) {
+				managed = true
+				break
+			}
+		}
+		if !managed {
+			otherTrailers = append(otherTrailers, t)
 		}
 	}
 
@@ -281,51 +648,182 @@ func (d *data) appendInfo(wd, msgFile string) error {
 	// the `Co-authored-by` lines.
 	msgStr = strings.TrimSpace(msgStr)
 
-	f, err := os.OpenFile(msgFile, os.O_WRONLY|os.O_TRUNC, 0)
-	if err != nil {
-		return errors.Wrapf(err, "open on commit msg file %s failed", msgFile)
+	info := &commitInfo{
+		msg:           msgStr,
+		issueID:       issueID,
+		issueIDSource: issueIDSource,
+		roles:         roles,
+		roleKeys:      roleKeys,
+		trailers:      otherTrailers,
+		comments:      comments,
 	}
-	defer f.Close()
 
-	if _, err := io.Copy(f, strings.NewReader(msgStr)); err != nil {
-		return errors.Wrapf(err, "write existing msg back failed")
+	if repo.NoreplyEmails && !roles.empty() {
+		roles.driver = noreplyDev(roles.driver)
+		for i, dev := range roles.navigators {
+			roles.navigators[i] = noreplyDev(dev)
+		}
+	}
+
+	var roster []string
+	for _, devID := range d.expandDevIDs(repo.sessionDevs()) {
+		if dev := d.lookupDev(devID); dev != nil {
+			roster = append(roster, dev.Email)
+		}
 	}
 
-	fmt.Fprintf(f, "\n\n")
+	for _, a := range devs.sorted(repo.CoAuthorOrder, roster) {
+		if a.dev.hasEmail(authorEmail) {
+			info.skipped = append(info.skipped, a)
+			continue
+		}
 
-	if issueID != "" {
-		if _, err := strconv.Atoi(issueID); err == nil {
-			fmt.Fprintf(f, "%s#%s\n\n", issueIDPrefix, issueID)
-		} else {
-			fmt.Fprintf(f, "%s%s\n\n", issueIDPrefix, issueID)
+		if repo.NoreplyEmails {
+			a.dev = noreplyDev(a.dev)
 		}
+
+		info.coAuthors = append(info.coAuthors, a)
+	}
+
+	// Sign-offs already in the message are kept as is.
+	info.signOffs = esigns
+	if repo.SignOff {
+		info.signOffs = d.signOffs(esigns, &dev{Name: authorName, Email: authorEmail}, info.coAuthors)
+	}
+
+	return info, nil
+}
+
+const soloID = "solo"
+
+// firstLineSelection is what the ids at the start of the first line ask
+// for. Plain dev ids replace the repo devs, while +id and -id add to or
+// drop from them. solo asks for no co-authors at all.
+type firstLineSelection struct {
+	issueID string
+	devIDs  []string
+	add     []string
+	remove  []string
+	solo    bool
+
+	// driver is the dev marked with a trailing *, like in [ak*,km].
+	driver string
+}
+
+func (s *firstLineSelection) relative() bool {
+	return len(s.add) != 0 || len(s.remove) != 0
+}
+
+// apply returns the devIDs after applying the add and remove modifiers.
+func (s *firstLineSelection) apply(devIDs []string) []string {
+	if s.solo {
+		return nil
+	}
+
+	removed := make(map[string]bool)
+	for _, id := range s.remove {
+		removed[id] = true
 	}
 
-	// We will write the authors back sorted by their email.
-	devEmails := make([]string, 0, len(devs))
-	for email := range devs {
-		devEmails = append(devEmails, email)
+	var result []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{devIDs, s.add} {
+		for _, id := range list {
+			if removed[id] || seen[id] {
+				continue
+			}
+			seen[id] = true
+			result = append(result, id)
+		}
 	}
-	sort.Strings(devEmails)
 
-	log.Printf("total devs: %v", devs)
+	return result
+}
 
-	for _, email := range devEmails {
-		dev := devs[email]
+func (d *data) parseFirstLineIDs(ids []string, issues *issueMatcher) (*firstLineSelection, error) {
+	sel := new(firstLineSelection)
+
+	for i, id := range ids {
+		driver := len(id) > 1 && strings.HasSuffix(id, "*")
+		if driver {
+			id = id[:len(id)-1]
+			if sel.driver != "" {
+				return nil, errors.New("more than one driver marked in the first line")
+			}
+		}
 
-		if dev.Email == authorEmail && dev.Name == authorName {
-			log.Printf("skipping %s (same as author)", dev)
+		if _, ok := d.lookupTeam(id); ok {
+			if driver {
+				return nil, errors.Errorf("team %s cannot be marked as driver", id)
+			}
+			sel.devIDs = append(sel.devIDs, d.expandDevIDs([]string{id})...)
 			continue
 		}
 
-		fmt.Fprintf(f, "Co-authored-by: %s <%s>\n", dev.Name, dev.Email)
-		log.Printf("added %s as author", dev)
+		if devID := d.resolveDevRef(id); devID != "" {
+			if d.Devs[devID].Retired {
+				return nil, retiredError(id)
+			}
+			if driver {
+				sel.driver = devID
+			}
+			sel.devIDs = append(sel.devIDs, devID)
+			continue
+		}
+
+		if driver && (id == soloID || id[0] != '+') {
+			return nil, errors.Errorf("%s cannot be marked as driver", id)
+		}
+
+		switch {
+		case id == soloID:
+			sel.solo = true
+			continue
+
+		case len(id) > 1 && (id[0] == '+' || id[0] == '-'):
+			devIDs := d.expandDevIDs([]string{id[1:]})
+			if _, ok := d.lookupTeam(id[1:]); !ok {
+				devID := d.resolveDevRef(id[1:])
+				if devID == "" {
+					return nil, errors.Errorf("non-existing dev %s provided in the first line%s", id[1:], d.didYouMean(id[1:]))
+				}
+				if id[0] == '+' && d.Devs[devID].Retired {
+					return nil, retiredError(id[1:])
+				}
+				devIDs = []string{devID}
+			}
+			if driver {
+				if len(devIDs) != 1 {
+					return nil, errors.Errorf("team %s cannot be marked as driver", id[1:])
+				}
+				sel.driver = devIDs[0]
+			}
+			if id[0] == '+' {
+				sel.add = append(sel.add, devIDs...)
+			} else {
+				sel.remove = append(sel.remove, devIDs...)
+			}
+			continue
+		}
+
+		if i == 0 && issues.match(id) {
+			// We will assume the the first id (if not a dev)
+			// is the issue id.
+			sel.issueID = id
+			continue
+		}
+		if i == 0 {
+			return nil, errors.Errorf("%s provided in the first line is neither a known dev nor a valid issue id (trackers: %s)%s", id, issues, d.didYouMean(id))
+		}
+		return nil, errors.Errorf("non-existing dev %s provided in the first line%s", id, d.didYouMean(id))
 	}
 
-	return nil
-}
+	if sel.solo && (len(sel.devIDs) != 0 || len(sel.add) != 0) {
+		return nil, errors.New("solo cannot be combined with other devs in the first line")
+	}
 
-var issueIDRegexp = regexp.MustCompile("#?.*[0-9]+")
+	return sel, nil
+}
 
 func firstLineIDs(msg string) ([]string, int) {
 	if len(msg) == 0 {
@@ -370,19 +868,26 @@ var gitVar = func(varStr string) (string, error) {
 	return string(output), nil
 }
 
+// nameEmail parses `Name <email>` idents, optionally prefixed by a
+// trailer key. The email is empty for malformed idents, which are common
+// enough in history.
 func nameEmail(ident string) (string, string) {
 	idx := strings.Index(ident, "<")
+	endIdx := strings.Index(ident, ">")
 	colonIdx := strings.Index(ident, ":")
 	nameStart := 0
-	if colonIdx != -1 && colonIdx < idx {
-		nameStart = colonIdx + 2
+	if colonIdx != -1 && (idx == -1 || colonIdx < idx) {
+		nameStart = colonIdx + 1
 	}
-	name := ident[nameStart : idx-1]
-	email := ident[idx+1 : strings.Index(ident, ">")]
+	if idx == -1 || endIdx < idx {
+		return strings.TrimSpace(ident[nameStart:]), ""
+	}
+	name := strings.TrimSpace(ident[nameStart:idx])
+	email := ident[idx+1 : endIdx]
 	return name, email
 }
 
-func existingIssueID(msg string) string {
+func existingIssueID(msg string, issues *issueMatcher) string {
 	scanner := bufio.NewScanner(strings.NewReader(msg))
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -392,7 +897,7 @@ func existingIssueID(msg string) string {
 		}
 
 		issueID := line[len(issueIDPrefix):]
-		if issueIDRegexp.MatchString(issueID) {
+		if issues.match(issueID) {
 			return issueID
 		}
 	}
@@ -400,8 +905,11 @@ func existingIssueID(msg string) string {
 	return ""
 }
 
+// existingDevs parses the Co-authored-by trailers in msg, dropping the
+// ones whose email was already seen.
 func existingDevs(msg string) []*dev {
 	var devs []*dev
+	seen := make(map[string]bool)
 
 	scanner := bufio.NewScanner(strings.NewReader(msg))
 	for scanner.Scan() {
@@ -412,6 +920,10 @@ func existingDevs(msg string) []*dev {
 		}
 
 		name, email := nameEmail(line)
+		if email == "" || seen[emailKey(email)] {
+			continue
+		}
+		seen[emailKey(email)] = true
 		devs = append(devs, &dev{Name: name, Email: email})
 	}
 
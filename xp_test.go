@@ -119,7 +119,7 @@ func TestDevString(t *testing.T) {
 func TestDataAddDev(t *testing.T) {
 	var d data
 
-	d.addDev("km", "Karan Misra", "karan@beef.com")
+	assert.NoError(t, d.addDev("km", "Karan Misra", "karan@beef.com"))
 
 	assert.Equal(t, &dev{Name: "Karan Misra", Email: "karan@beef.com"}, d.Devs["km"])
 
@@ -132,12 +132,55 @@ func TestDataAddDev(t *testing.T) {
 		},
 	}
 
-	d.addDev("km", "Karan Misra", "karan@beef.com")
+	assert.NoError(t, d.addDev("km", "Karan Misra", "karan@beef.com"))
 
 	assert.Equal(t, &dev{Name: "Karan Misra", Email: "karan@beef.com"}, d.Devs["km"])
 	assert.Equal(t, &dev{Name: "akshat", Email: "akshat@beef.com"}, d.Devs["ak"])
 }
 
+func TestDataAddDevExisting(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"km": {
+				Name:    "Karan Misra",
+				Email:   "karan@beef.com",
+				Emails:  []string{"karan@home.com"},
+				Aliases: []string{"kidoman"},
+				Handle:  "kidoman",
+				ForgeID: 1234,
+				SignOff: true,
+				Retired: true,
+			},
+			"ak": {Name: "akshat", Email: "akshat@beef.com"},
+		},
+	}
+
+	// Settings other than the name and email are kept.
+	assert.NoError(t, d.addDev("kidoman", "Karan M", "KARAN@home.com"))
+	assert.Equal(t, &dev{
+		Name:    "Karan M",
+		Email:   "KARAN@home.com",
+		Emails:  []string{"karan@beef.com"},
+		Aliases: []string{"kidoman"},
+		Handle:  "kidoman",
+		ForgeID: 1234,
+		SignOff: true,
+		Retired: true,
+	}, d.Devs["km"])
+	assert.NotContains(t, d.Devs, "kidoman")
+
+	err := d.addDev("kmisra", "Karan Misra", "karan@beef.com")
+	if assert.Error(t, err) {
+		assert.Equal(t, "email karan@beef.com already belongs to dev km", err.Error())
+	}
+	assert.NotContains(t, d.Devs, "kmisra")
+
+	err = d.addDev("ak", "akshat", "karan@home.com")
+	if assert.Error(t, err) {
+		assert.Equal(t, "email karan@home.com already belongs to dev km", err.Error())
+	}
+}
+
 func TestDataLookupDev(t *testing.T) {
 	var d data
 
@@ -186,6 +229,14 @@ func TestDataValidateDevs(t *testing.T) {
 			ids:    []string{"anand"},
 			errMsg: "no dev with id anand found",
 		},
+		{
+			ids:    []string{"ak", "mk"},
+			errMsg: "no dev with id mk found (did you mean ak or km?)",
+		},
+		{
+			ids:    []string{"karan"},
+			errMsg: "no dev with id karan found (did you mean km?)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -256,6 +307,41 @@ func TestDataAddRepo(t *testing.T) {
 	}
 }
 
+func TestDataAddRepoAgain(t *testing.T) {
+	d := data{
+		Devs: map[string]*dev{
+			"ak": {Name: "akshat", Email: "akshat@beef.com"},
+			"km": {Name: "Karan Misra", Email: "karan@beef.com"},
+		},
+		Repos: map[string]*repo{
+			"/a": {
+				Devs:         []string{"km"},
+				IssueID:      "o-1",
+				Trackers:     []*tracker{{Type: "linear"}},
+				Policy:       &policy{RejectPrefix: true},
+				SignOff:      true,
+				ScopeIssueID: true,
+				Mob:          &mob{Devs: []string{"ak", "km"}},
+			},
+		},
+	}
+
+	assert.NoError(t, d.addRepo("/a", []string{"ak"}, ""))
+	assert.Equal(t, &repo{
+		Devs:         []string{"ak"},
+		IssueID:      "o-1",
+		Trackers:     []*tracker{{Type: "linear"}},
+		Policy:       &policy{RejectPrefix: true},
+		SignOff:      true,
+		ScopeIssueID: true,
+		Mob:          &mob{Devs: []string{"ak", "km"}},
+	}, d.Repos["/a"])
+
+	assert.NoError(t, d.addRepo("/a", nil, "o-2"))
+	assert.Equal(t, []string{"ak"}, d.Repos["/a"].Devs)
+	assert.Equal(t, "o-2", d.Repos["/a"].IssueID)
+}
+
 func TestInitRepo(t *testing.T) {
 	tests := []struct {
 		desc      string
@@ -344,19 +430,18 @@ func TestInitRepo(t *testing.T) {
 			continue
 		}
 
-		for _, hookFile := range hookFiles {
-			validateHook(t, path.Join(repoDir, ".git", hookFile))
-		}
+		validateHook(t, path.Join(repoDir, ".git", "hooks/prepare-commit-msg"), "add-info")
+		validateHook(t, path.Join(repoDir, ".git", "hooks/commit-msg"), "check-msg")
 	}
 }
 
-func validateHook(t *testing.T, hook string) {
+func validateHook(t *testing.T, hook, cmd string) {
 	data, err := ioutil.ReadFile(hook)
 	if !assert.NoError(t, err) {
 		return
 	}
 
-	assert.Equal(t, "#!/bin/sh\n/path/to/xp add-info $1\n", string(data))
+	assert.Equal(t, "#!/bin/sh\n/path/to/xp "+cmd+" $1\n", string(data))
 }
 
 func TestLookupRepo(t *testing.T) {
@@ -460,7 +545,7 @@ func TestAppendInfo(t *testing.T) {
 	d := data{
 		Devs: map[string]*dev{
 			"karan": &dev{
-				Name: "Karan Misra", Email: "karan@beef.com",
+				Name: "Karan Misra", Email: "karan@beef.com", Emails: []string{"karan@home.com"},
 			},
 			"anand": &dev{
 				Name: "Anand Shankar", Email: "anand@beef.com",
@@ -468,18 +553,86 @@ func TestAppendInfo(t *testing.T) {
 			"akshat": &dev{
 				Name: "Akshat Shah", Email: "akshat@beef.com",
 			},
+			"priya": &dev{
+				Name: "Priya Rao", Email: "priya@beef.com", Handle: "priyar", ForgeID: 1234,
+			},
+			"neha": &dev{
+				Name: "Neha Jain", Email: "neha@beef.com", Aliases: []string{"nj"}, SignOff: true,
+			},
+			"rohan": &dev{
+				Name: "Rohan Das", Email: "rohan@beef.com", Retired: true,
+			},
 		},
 		Repos: map[string]*repo{
 			"/a": &repo{
 				Devs: []string{"karan"},
 			},
+			"/s": &repo{
+				Devs:    []string{"karan", "neha", "anand"},
+				SignOff: true,
+			},
+			"/e": &repo{
+				Devs:          []string{"karan", "priya"},
+				NoreplyEmails: true,
+			},
+			"/f": &repo{
+				Devs: []string{"@payments"},
+			},
+			"/g": &repo{
+				Devs: []string{"karan", "rohan"},
+			},
+			"/r": &repo{
+				Devs:         []string{"anand", "karan"},
+				Driver:       "anand",
+				Navigators:   []string{"karan"},
+				RoleTrailers: &roleTrailers{Driver: "Driver"},
+			},
+			"/m": &repo{
+				Devs: []string{"karan"},
+				Mob: &mob{
+					Devs: []string{"anand", "karan", "akshat"},
+				},
+			},
+			"/c": &repo{
+				Devs: []string{"karan", "anand"},
+			},
+			"/d": &repo{
+				ScopeIssueID: true,
+			},
+			"/i": &repo{
+				Devs:         []string{"anand"},
+				IssueID:      "1337",
+				ScopeIssueID: true,
+			},
+			"/b": &repo{
+				Trackers: []*tracker{
+					{Type: "custom", Pattern: "sc-[0-9]+"},
+				},
+			},
+			"/n": &repo{
+				Devs:          []string{"priya", "akshat", "neha"},
+				CoAuthorOrder: orderName,
+			},
+			"/o": &repo{
+				Devs:          []string{"priya", "akshat", "neha"},
+				CoAuthorOrder: orderFirstLine,
+			},
+			"/p": &repo{
+				Devs:          []string{"priya", "akshat", "neha"},
+				CoAuthorOrder: orderRoster,
+			},
 		},
 	}
 
+	d.Teams = map[string][]string{
+		"payments": {"karan", "anand"},
+	}
+
 	tests := []struct {
 		desc        string
 		wd          string
 		author      string
+		committer   string
 		msg         string
 		errMsg      string
 		expectedMsg string
@@ -496,6 +649,41 @@ func TestAppendInfo(t *testing.T) {
 			msg:         "Line 1",
 			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
 		},
+		{
+			desc:        "committer differs from author",
+			author:      "Akshat Shah <akshat@beef.com>",
+			committer:   "Anand Shankar <anand@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "--author is a repo dev",
+			author:      "Karan M <KARAN@beef.com>",
+			committer:   "Anand Shankar <anand@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "solo with a different committer",
+			author:      "Anand Shankar <anand@beef.com>",
+			committer:   "Karan Misra <karan@beef.com>",
+			msg:         "[solo] Line 1",
+			expectedMsg: "Line 1\n\n",
+		},
+		{
+			desc:        "committer not in the roster",
+			author:      "Anand Shankar <anand@beef.com>",
+			committer:   "Release Bot <bot@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "committer not in the roster and no other devs",
+			author:      "Anand Shankar <anand@beef.com>",
+			committer:   "Shobhit <shobhit@beef.com>",
+			msg:         "[-karan] Line 1",
+			expectedMsg: "Line 1\n\n",
+		},
 		{
 			desc:        "co-author added in first line",
 			author:      "Karan Misra <karan@beef.com>",
@@ -512,6 +700,18 @@ func TestAppendInfo(t *testing.T) {
 			desc:   "unknown dev in first line",
 			author: "Karan Misra <karan@beef.com>",
 			msg:    "[shobhit] Line 1",
+			errMsg: "shobhit provided in the first line is neither a known dev nor a valid issue id (trackers: jira, github)",
+		},
+		{
+			desc:   "mistyped dev with a digit in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[anand2] Line 1",
+			errMsg: "anand2 provided in the first line is neither a known dev nor a valid issue id (trackers: jira, github) (did you mean anand?)",
+		},
+		{
+			desc:   "unknown dev after issue id in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[GOJ-1337,shobhit] Line 1",
 			errMsg: "non-existing dev shobhit provided in the first line",
 		},
 		{
@@ -574,6 +774,385 @@ func TestAppendInfo(t *testing.T) {
 			msg:         "Line 1\n\nIssue-id: GOJ-1337",
 			expectedMsg: "Line 1\n\nIssue-id: GOJ-1337\n\n",
 		},
+		{
+			desc:   "mistyped dev after issue id in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[GOJ-1337,aknad] Line 1",
+			errMsg: "non-existing dev aknad provided in the first line (did you mean anand?)",
+		},
+		{
+			desc:   "mistyped dev added in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[+akshta] Line 1",
+			errMsg: "non-existing dev akshta provided in the first line (did you mean akshat?)",
+		},
+		{
+			desc:        "dev added to repo devs in first line",
+			author:      "Anand Shankar <anand@beef.com>",
+			msg:         "[+akshat] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "dev dropped from repo devs in first line",
+			wd:          "/c",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "[-anand] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "all repo devs dropped in first line",
+			author:      "Anand Shankar <anand@beef.com>",
+			msg:         "[-karan] Line 1\n\nCo-authored-by: Akshat Shah <akshat@beef.com>",
+			expectedMsg: "Line 1\n\n",
+		},
+		{
+			desc:        "issue id and relative devs in first line",
+			wd:          "/c",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[GOJ-1337|+akshat|-anand] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: GOJ-1337\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\n",
+		},
+		{
+			desc:        "solo in first line",
+			author:      "Anand Shankar <anand@beef.com>",
+			msg:         "[solo] Line 1\n\nCo-authored-by: Akshat Shah <akshat@beef.com>",
+			expectedMsg: "Line 1\n\n",
+		},
+		{
+			desc:        "issue id and solo in first line",
+			author:      "Anand Shankar <anand@beef.com>",
+			msg:         "[1337,solo] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: #1337\n\n",
+		},
+		{
+			desc:   "solo with other devs in first line",
+			author: "Anand Shankar <anand@beef.com>",
+			msg:    "[solo,akshat] Line 1",
+			errMsg: "solo cannot be combined with other devs in the first line",
+		},
+		{
+			desc:   "unknown dev added in first line",
+			author: "Anand Shankar <anand@beef.com>",
+			msg:    "[+shobhit] Line 1",
+			errMsg: "non-existing dev shobhit provided in the first line",
+		},
+		{
+			desc:        "co-author after conventional commit header",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "feat(api): [anand] Line 1",
+			expectedMsg: "feat(api): Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "issue id and co-author after breaking conventional commit header",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "fix!: [GOJ-1337,anand] Line 1",
+			expectedMsg: "fix!: Line 1\n\nIssue-id: GOJ-1337\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "co-author before conventional commit header",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[anand] feat(api): Line 1",
+			expectedMsg: "feat(api): Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "issue id in scope not enabled",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "feat(GOJ-1337): Line 1",
+			expectedMsg: "feat(GOJ-1337): Line 1\n\n",
+		},
+		{
+			desc:        "issue id in scope",
+			wd:          "/d",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[anand] feat(GOJ-1337): Line 1",
+			expectedMsg: "feat(GOJ-1337): Line 1\n\nIssue-id: GOJ-1337\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "issue id in first line and scope",
+			wd:          "/d",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "feat(GOJ-1337): [GOJ-1] Line 1",
+			expectedMsg: "feat(GOJ-1337): Line 1\n\nIssue-id: GOJ-1\n\n",
+		},
+		{
+			desc:        "scope which is not an issue id",
+			wd:          "/d",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "feat(api): Line 1",
+			expectedMsg: "feat(api): Line 1\n\n",
+		},
+		{
+			desc:        "repo issue id",
+			wd:          "/i",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[akshat] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: #1337\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\n",
+		},
+		{
+			desc:        "repo issue id overridden in first line",
+			wd:          "/i",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[GOJ-1] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "repo issue id overridden in trailer",
+			wd:          "/i",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1\n\nIssue-id: GOJ-2",
+			expectedMsg: "Line 1\n\nIssue-id: GOJ-2\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "repo issue id overridden in scope",
+			wd:          "/i",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "feat(GOJ-3): Line 1",
+			expectedMsg: "feat(GOJ-3): Line 1\n\nIssue-id: GOJ-3\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "co-author by handle in first line",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[@PriyaR] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Priya Rao <priya@beef.com>\n",
+		},
+		{
+			desc:        "co-author by handle added in first line",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "[GOJ-1|+@priyar] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Karan Misra <karan@beef.com>\nCo-authored-by: Priya Rao <priya@beef.com>\n",
+		},
+		{
+			desc:   "unknown handle in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[GOJ-1,@shobhit] Line 1",
+			errMsg: "non-existing dev @shobhit provided in the first line",
+		},
+		{
+			desc:        "team in first line",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[GOJ-1,@payments] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "team added in first line",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "[+@payments] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "team as repo devs",
+			wd:          "/f",
+			author:      "Anand Shankar <anand@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "dev dropped from team as repo devs",
+			wd:          "/f",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "[-karan] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "co-author by alias in first line",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[nj] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Neha Jain <neha@beef.com>\n",
+		},
+		{
+			desc:   "retired dev in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[GOJ-1,rohan] Line 1",
+			errMsg: "dev rohan is retired and can no longer be credited",
+		},
+		{
+			desc:   "retired dev added in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[+rohan] Line 1",
+			errMsg: "dev rohan is retired and can no longer be credited",
+		},
+		{
+			desc:        "retired dev in repo devs",
+			wd:          "/g",
+			author:      "Anand Shankar <anand@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "retired dev in existing trailer",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1\n\nCo-authored-by: Rohan Das <rohan@beef.com>",
+			expectedMsg: "Line 1\n\nCo-authored-by: Rohan Das <rohan@beef.com>\n",
+		},
+		{
+			desc:        "mob devs",
+			wd:          "/m",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "driver marked in first line",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "[GOJ-1,karan*,anand] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: GOJ-1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\nCo-authored-by: Karan Misra <karan@beef.com>\nPair-driver: Karan Misra <karan@beef.com>\nPair-navigator: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "author marked as driver in first line",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[karan*] Line 1",
+			expectedMsg: "Line 1\n\nPair-driver: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "existing driver without co-authors",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1\n\nPair-driver: Karan Misra <karan@beef.com>\n",
+			expectedMsg: "Line 1\n\nPair-driver: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "driver added in first line",
+			author:      "Anand Shankar <anand@beef.com>",
+			msg:         "[+akshat*] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\nCo-authored-by: Karan Misra <karan@beef.com>\nPair-driver: Akshat Shah <akshat@beef.com>\nPair-navigator: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:   "issue id marked as driver in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[GOJ-1*,anand] Line 1",
+			errMsg: "GOJ-1 cannot be marked as driver",
+		},
+		{
+			desc:   "two drivers marked in first line",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[akshat*,anand*] Line 1",
+			errMsg: "more than one driver marked in the first line",
+		},
+		{
+			desc:        "repo roles with custom trailer key",
+			wd:          "/r",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\nCo-authored-by: Karan Misra <karan@beef.com>\nDriver: Anand Shankar <anand@beef.com>\nPair-navigator: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "existing roles kept",
+			wd:          "/r",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\nDriver: Karan Misra <karan@beef.com>\n",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\nDriver: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "repo roles replaced by first line",
+			wd:          "/r",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "[karan] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "noreply email for repo devs",
+			wd:          "/e",
+			author:      "Akshat Shah <akshat@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\nCo-authored-by: Priya Rao <1234+priyar@users.noreply.github.com>\n",
+		},
+		{
+			desc:        "noreply email author skipped",
+			wd:          "/e",
+			author:      "Priya Rao <1234+priyar@users.noreply.github.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "custom tracker issue id in first line",
+			wd:          "/b",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[sc-42] Line 1",
+			expectedMsg: "Line 1\n\nIssue-id: sc-42\n\n",
+		},
+		{
+			desc:   "issue id of unconfigured tracker in first line",
+			wd:     "/b",
+			author: "Karan Misra <karan@beef.com>",
+			msg:    "[GOJ-1337] Line 1",
+			errMsg: "GOJ-1337 provided in the first line is neither a known dev nor a valid issue id (trackers: custom=sc-[0-9]+)",
+		},
+		{
+			desc:        "existing co-authors differing in case and +tag",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1\n\nCo-authored-by: Akshat Shah <Ak@Beef.com>\nCo-authored-by: Akshat Shah <ak+xp@beef.com>\nCo-authored-by: Anand Shankar <anand@beef.com>",
+			expectedMsg: "Line 1\n\nCo-authored-by: Akshat Shah <Ak@Beef.com>\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "author with +tag email",
+			author:      "Karan Misra <Karan+work@beef.com>",
+			msg:         "[karan,anand] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "author with other email",
+			author:      "Karan Misra <karan@home.com>",
+			msg:         "[karan,anand] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "ordered by name",
+			wd:          "/n",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Akshat Shah <akshat@beef.com>\nCo-authored-by: Neha Jain <neha@beef.com>\nCo-authored-by: Priya Rao <priya@beef.com>\n",
+		},
+		{
+			desc:        "ordered as given in the first line",
+			wd:          "/o",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[neha,anand,akshat] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Neha Jain <neha@beef.com>\nCo-authored-by: Anand Shankar <anand@beef.com>\nCo-authored-by: Akshat Shah <akshat@beef.com>\n",
+		},
+		{
+			desc:        "ordered as in the roster",
+			wd:          "/p",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[anand,neha,priya] Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Priya Rao <priya@beef.com>\nCo-authored-by: Neha Jain <neha@beef.com>\nCo-authored-by: Anand Shankar <anand@beef.com>\n",
+		},
+		{
+			desc:        "sign-off for author and opted in co-authors",
+			wd:          "/s",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\nCo-authored-by: Neha Jain <neha@beef.com>\nSigned-off-by: Karan Misra <karan@beef.com>\nSigned-off-by: Neha Jain <neha@beef.com>\n",
+		},
+		{
+			desc:        "sign-off already added by git commit -s",
+			wd:          "/s",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[anand] Line 1\n\nSigned-off-by: Karan Misra <Karan@beef.com>",
+			expectedMsg: "Line 1\n\nCo-authored-by: Anand Shankar <anand@beef.com>\nSigned-off-by: Karan Misra <Karan@beef.com>\n",
+		},
+		{
+			desc:        "existing sign-off kept without repo sign-off",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1\n\nSigned-off-by: Karan Misra <karan@beef.com>",
+			expectedMsg: "Line 1\n\nSigned-off-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "other trailers after a sign-off are kept",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "[anand] Line 1\n\nSigned-off-by: Karan Misra <karan@beef.com>\nChange-Id: I12ab\nReviewed-by: Neha Jain <neha@beef.com>\nCc: Someone\n  Else <some@one.com>",
+			expectedMsg: "Line 1\n\nChange-Id: I12ab\nReviewed-by: Neha Jain <neha@beef.com>\nCc: Someone\n  Else <some@one.com>\nCo-authored-by: Anand Shankar <anand@beef.com>\nSigned-off-by: Karan Misra <karan@beef.com>\n",
+		},
+		{
+			desc:        "trailer prefixes in the body are left alone",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1\n\nWe used to write\nSigned-off-by: here, and Co-authored-by: there.\n\nLine 3",
+			expectedMsg: "Line 1\n\nWe used to write\nSigned-off-by: here, and Co-authored-by: there.\n\nLine 3\n\n",
+		},
+		{
+			desc:        "git comments stay after the trailers",
+			author:      "Karan Misra <karan@beef.com>",
+			msg:         "Line 1\n\nCo-authored-by: Neha Jain <neha@beef.com>\n\n# Please enter the commit message\n#\n" + scissorsLine + "\ndiff --git a/a b/a\n",
+			expectedMsg: "Line 1\n\nCo-authored-by: Neha Jain <neha@beef.com>\n\n# Please enter the commit message\n#\n" + scissorsLine + "\ndiff --git a/a b/a\n",
+		},
 	}
 
 	oldGitVar := gitVar
@@ -594,11 +1173,19 @@ func TestAppendInfo(t *testing.T) {
 			panic(err)
 		}
 
-		gitVar = func(_ string) (string, error) {
+		gitVar = func(name string) (string, error) {
+			if name == "GIT_COMMITTER_IDENT" && tt.committer != "" {
+				return tt.committer, nil
+			}
 			return tt.author, nil
 		}
 
-		err = d.appendInfo("/a", f.Name())
+		wd := tt.wd
+		if wd == "" {
+			wd = "/a"
+		}
+
+		err = d.appendInfo(wd, f.Name())
 
 		if tt.errMsg != "" {
 			if !assert.Error(t, err) {
@@ -663,6 +1250,21 @@ func TestFirstLineIDs(t *testing.T) {
 			ids: []string{"GOJ-1337", "a", "b", "c"},
 			idx: 16,
 		},
+		{
+			msg: "[+a,-b] hello there",
+			ids: []string{"+a", "-b"},
+			idx: 7,
+		},
+		{
+			msg: "[GOJ-1337|+a|-b] hello there",
+			ids: []string{"GOJ-1337", "+a", "-b"},
+			idx: 16,
+		},
+		{
+			msg: "[solo] hello there",
+			ids: []string{"solo"},
+			idx: 6,
+		},
 	}
 
 	for _, tt := range tests {
@@ -689,6 +1291,18 @@ func TestNameEmail(t *testing.T) {
 			"Co-authored-by: name <email>",
 			"name", "email",
 		},
+		{
+			"Co-authored-by: <email>",
+			"", "email",
+		},
+		{
+			"Co-authored-by: name",
+			"name", "",
+		},
+		{
+			"Co-authored-by: name <email",
+			"name <email", "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -697,3 +1311,41 @@ func TestNameEmail(t *testing.T) {
 		assert.Equal(t, tt.email, email)
 	}
 }
+
+func TestFirstLineSelectionApply(t *testing.T) {
+	tests := []struct {
+		sel    firstLineSelection
+		devIDs []string
+		result []string
+	}{
+		{
+			sel:    firstLineSelection{},
+			devIDs: []string{"a", "b"},
+			result: []string{"a", "b"},
+		},
+		{
+			sel:    firstLineSelection{add: []string{"c", "a"}},
+			devIDs: []string{"a", "b"},
+			result: []string{"a", "b", "c"},
+		},
+		{
+			sel:    firstLineSelection{add: []string{"c"}, remove: []string{"a", "d"}},
+			devIDs: []string{"a", "b"},
+			result: []string{"b", "c"},
+		},
+		{
+			sel:    firstLineSelection{remove: []string{"a"}},
+			devIDs: []string{"a"},
+			result: nil,
+		},
+		{
+			sel:    firstLineSelection{solo: true},
+			devIDs: []string{"a", "b"},
+			result: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.result, tt.sel.apply(tt.devIDs))
+	}
+}